@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"mayhamapi/scoring"
+)
+
+// runCommand runs a maintenance subcommand against the database and prints its report as JSON
+func runCommand(scoringService *scoring.ScoringService, name string, args []string) error {
+	switch name {
+	case "recompute":
+		return runRecompute(scoringService, args)
+	case "rebuild-stats":
+		return runRebuildStats(scoringService, args)
+	default:
+		return fmt.Errorf("unknown command %q", name)
+	}
+}
+
+// runRecompute rescores matches from their stored scores:
+//
+//	mayhamapi recompute [-tournament ID] [-dry-run]
+func runRecompute(scoringService *scoring.ScoringService, args []string) error {
+	flags := flag.NewFlagSet("recompute", flag.ContinueOnError)
+	tournament := flags.String("tournament", "", "only rescore this tournament's matches")
+	dryRun := flags.Bool("dry-run", false, "report the results that would change without saving them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var tournamentID *string
+	if *tournament != "" {
+		tournamentID = tournament
+	}
+
+	report, err := scoringService.Recompute(tournamentID, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	return printReport(report)
+}
+
+// runRebuildStats regenerates player_stats from stored match results:
+//
+//	mayhamapi rebuild-stats [-tournament ID]
+func runRebuildStats(scoringService *scoring.ScoringService, args []string) error {
+	flags := flag.NewFlagSet("rebuild-stats", flag.ContinueOnError)
+	tournament := flags.String("tournament", "", "only rebuild this tournament's stats")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var tournamentID *string
+	if *tournament != "" {
+		tournamentID = tournament
+	}
+
+	rebuild, err := scoringService.RebuildPlayerStats(tournamentID)
+	if err != nil {
+		return err
+	}
+
+	return printReport(rebuild)
+}
+
+func printReport(report interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
@@ -55,6 +55,11 @@ CREATE TABLE IF NOT EXISTS teams (
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Cup settings: points needed to win the cup (NULL means more than half the points on offer),
+-- and the holder, who retains the cup on a tie
+ALTER TABLE tournaments ADD COLUMN IF NOT EXISTS target_points DECIMAL(5,1);
+ALTER TABLE tournaments ADD COLUMN IF NOT EXISTS defending_team_id UUID REFERENCES teams(id) ON DELETE SET NULL;
+
 -- Players assigned to teams for a tournament
 CREATE TABLE IF NOT EXISTS team_members (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -78,16 +83,57 @@ CREATE TABLE IF NOT EXISTS rounds (
     UNIQUE(tournament_id, round_number)
 );
 
+-- Courses, tees and the per-tee scorecard
+CREATE TABLE IF NOT EXISTS courses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    location VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS tees (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    course_id UUID REFERENCES courses(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL, -- e.g., "Blue", "White"
+    color VARCHAR(50),
+    course_rating DECIMAL(4,1),
+    slope INT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(course_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS holes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tee_id UUID REFERENCES tees(id) ON DELETE CASCADE,
+    hole_number INT NOT NULL,
+    par INT NOT NULL,
+    stroke_index INT NOT NULL, -- 1 = hardest hole
+    yardage INT,
+    UNIQUE(tee_id, hole_number)
+);
+
+-- Course and tee played in each round
+ALTER TABLE rounds ADD COLUMN IF NOT EXISTS course_id UUID REFERENCES courses(id);
+ALTER TABLE rounds ADD COLUMN IF NOT EXISTS tee_id UUID REFERENCES tees(id);
+
+-- Default points_available for matches created in a round (e.g. 2 for final-day singles)
+ALTER TABLE rounds ADD COLUMN IF NOT EXISTS points_per_match DECIMAL(3,1) NOT NULL DEFAULT 1.0;
+
 -- Match formats/types
 CREATE TABLE IF NOT EXISTS match_formats (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     name VARCHAR(100) NOT NULL UNIQUE, -- e.g., "2v2 Scramble", "Singles Match Play", "High-Low"
     description TEXT,
     players_per_side INT NOT NULL, -- 1 for singles, 2 for pairs
-    scoring_type VARCHAR(50) NOT NULL, -- match_play, stroke_play, scramble, shamble, high_low
+    scoring_type VARCHAR(50) NOT NULL, -- match_play, stroke_play, stableford, scramble, shamble, high_low, nassau, custom
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Optional per-format scoring settings (e.g. Stableford points table)
+ALTER TABLE match_formats ADD COLUMN IF NOT EXISTS config JSONB NOT NULL DEFAULT '{}';
+
 -- Matches within a round
 CREATE TABLE IF NOT EXISTS matches (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -106,6 +152,34 @@ CREATE TABLE IF NOT EXISTS matches (
     UNIQUE(round_id, match_number)
 );
 
+-- Handicap settings per match (added after initial release)
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS handicap_mode VARCHAR(50) DEFAULT 'gross'; -- gross, full_difference, full_handicap
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS handicap_allowance DECIMAL(5,2); -- percentage, NULL uses the format default
+
+-- Official match play result, e.g. "3&2", "1 UP", "A/S", "Halved"
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS result_text VARCHAR(20);
+
+-- A halved match splits its points, so team points need two decimals (e.g. 0.75 each for a 1.5 point match)
+ALTER TABLE matches ALTER COLUMN team1_points TYPE DECIMAL(4,2);
+ALTER TABLE matches ALTER COLUMN team2_points TYPE DECIMAL(4,2);
+
+-- Knockout/deciding matches continue to sudden-death playoff holes (numbered after the match's last hole) when tied
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS must_produce_winner BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Holes played, in order (e.g. 7-12, or 10-18 then 1-9 for a shotgun start on 10)
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS starting_hole INT NOT NULL DEFAULT 1;
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS hole_sequence INT[]; -- NULL means `holes` consecutive holes from starting_hole
+
+-- Live win probabilities, recalculated with each score submission; NULL until first scored
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS team1_win_probability DECIMAL(5,4);
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS halve_probability DECIMAL(5,4);
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS team2_win_probability DECIMAL(5,4);
+
+-- Live progress, saved with each result so the live feed doesn't have to rescore matches
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS holes_completed INT NOT NULL DEFAULT 0;
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS team1_up INT NOT NULL DEFAULT 0; -- positive when team 1 leads
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS scoring_unit VARCHAR(10) NOT NULL DEFAULT 'holes'; -- holes, strokes or points
+
 -- Players participating in a specific match
 CREATE TABLE IF NOT EXISTS match_players (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -116,6 +190,9 @@ CREATE TABLE IF NOT EXISTS match_players (
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Optional per-player tee, overriding the round's tee
+ALTER TABLE match_players ADD COLUMN IF NOT EXISTS tee_id UUID REFERENCES tees(id);
+
 -- Hole-by-hole scores
 CREATE TABLE IF NOT EXISTS hole_scores (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -128,6 +205,9 @@ CREATE TABLE IF NOT EXISTS hole_scores (
     UNIQUE(match_id, hole_number, user_id)
 );
 
+-- Score after the format's caps (net double bogey, pick up at X), kept alongside raw strokes
+ALTER TABLE hole_scores ADD COLUMN IF NOT EXISTS adjusted_strokes INT;
+
 -- Hole results (who won each hole)
 CREATE TABLE IF NOT EXISTS hole_results (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -143,6 +223,55 @@ CREATE TABLE IF NOT EXISTS hole_results (
     UNIQUE(match_id, hole_number)
 );
 
+-- Individual point contests for formats that decide more than one per hole (e.g. split high-low)
+ALTER TABLE hole_results ADD COLUMN IF NOT EXISTS contests JSONB NOT NULL DEFAULT '[]';
+
+-- Conceded holes, putts and matches; authoritative over submitted strokes
+CREATE TABLE IF NOT EXISTS match_concessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    match_id UUID REFERENCES matches(id) ON DELETE CASCADE,
+    concession_type VARCHAR(20) NOT NULL, -- hole, putt, match
+    hole_number INT, -- NULL for match concessions
+    conceding_team_id UUID REFERENCES teams(id),
+    user_id UUID REFERENCES users(id), -- putt concessions: player whose putt was conceded
+    strokes INT, -- putt concessions: player's score for the hole counting the conceded putt
+    recorded_by UUID REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Audit trail of score submissions, edits and concessions
+CREATE TABLE IF NOT EXISTS match_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    match_id UUID REFERENCES matches(id) ON DELETE CASCADE,
+    event_type VARCHAR(50) NOT NULL, -- score_submitted, score_updated, concession
+    hole_number INT,
+    user_id UUID REFERENCES users(id), -- player the event concerns
+    recorded_by UUID REFERENCES users(id), -- user who made the change
+    details JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Manually called Nassau presses
+CREATE TABLE IF NOT EXISTS match_presses (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    match_id UUID REFERENCES matches(id) ON DELETE CASCADE,
+    team_id UUID REFERENCES teams(id), -- side that called the press
+    start_hole INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(match_id, start_hole)
+);
+
+-- Skins side game settings per round
+CREATE TABLE IF NOT EXISTS skins_games (
+    round_id UUID PRIMARY KEY REFERENCES rounds(id) ON DELETE CASCADE,
+    mode VARCHAR(20) NOT NULL DEFAULT 'gross', -- gross, net
+    carryovers BOOLEAN NOT NULL DEFAULT TRUE,
+    validation_birdie BOOLEAN NOT NULL DEFAULT FALSE,
+    pot DECIMAL(10,2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
 -- Player statistics/leaderboard
 CREATE TABLE IF NOT EXISTS player_stats (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -159,6 +288,11 @@ CREATE TABLE IF NOT EXISTS player_stats (
     UNIQUE(tournament_id, user_id)
 );
 
+-- Match records, kept alongside points and holes by the stats aggregator
+ALTER TABLE player_stats ADD COLUMN IF NOT EXISTS matches_won INT NOT NULL DEFAULT 0;
+ALTER TABLE player_stats ADD COLUMN IF NOT EXISTS matches_lost INT NOT NULL DEFAULT 0;
+ALTER TABLE player_stats ADD COLUMN IF NOT EXISTS matches_tied INT NOT NULL DEFAULT 0;
+
 -- Indexes for performance
 CREATE INDEX IF NOT EXISTS idx_tournaments_status ON tournaments(status);
 CREATE INDEX IF NOT EXISTS idx_tournaments_group ON tournaments(group_id);
@@ -167,7 +301,12 @@ CREATE INDEX IF NOT EXISTS idx_matches_round ON matches(round_id);
 CREATE INDEX IF NOT EXISTS idx_match_players_match ON match_players(match_id);
 CREATE INDEX IF NOT EXISTS idx_hole_scores_match ON hole_scores(match_id, hole_number);
 CREATE INDEX IF NOT EXISTS idx_hole_results_match ON hole_results(match_id);
+CREATE INDEX IF NOT EXISTS idx_match_presses_match ON match_presses(match_id);
+CREATE INDEX IF NOT EXISTS idx_match_concessions_match ON match_concessions(match_id);
+CREATE INDEX IF NOT EXISTS idx_match_events_match ON match_events(match_id, created_at);
 CREATE INDEX IF NOT EXISTS idx_player_stats_tournament ON player_stats(tournament_id);
+CREATE INDEX IF NOT EXISTS idx_tees_course ON tees(course_id);
+CREATE INDEX IF NOT EXISTS idx_holes_tee ON holes(tee_id, hole_number);
 CREATE INDEX IF NOT EXISTS idx_team_members_team ON team_members(team_id);
 CREATE INDEX IF NOT EXISTS idx_group_members_group ON group_members(group_id);
 CREATE INDEX IF NOT EXISTS idx_group_members_user ON group_members(user_id);
@@ -179,5 +318,16 @@ INSERT INTO match_formats (name, description, players_per_side, scoring_type) VA
     ('2v2 Best Ball', 'Two-person team best ball', 2, 'best_ball'),
     ('2v2 Alternate Shot', 'Two-person alternate shot', 2, 'alternate_shot'),
     ('High-Low', 'Best and worst score combination', 2, 'high_low'),
-    ('Shamble', 'Drive scramble, then individual play', 2, 'shamble')
+    ('Shamble', 'Drive scramble, then individual play', 2, 'shamble'),
+    ('Singles Stroke Play', 'Individual medal play, lowest total wins', 1, 'stroke_play'),
+    ('2v2 Stroke Play', 'Team medal play, combined totals', 2, 'stroke_play'),
+    ('Stableford', 'Points per hole relative to par, highest total wins', 1, 'stableford'),
+    ('2v2 Stableford', 'Team Stableford, combined points', 2, 'stableford')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO match_formats (name, description, players_per_side, scoring_type, config) VALUES
+    ('Nassau', 'Front nine, back nine and overall bets with manual presses', 1, 'nassau', '{}'),
+    ('2v2 Nassau', 'Best ball Nassau with manual presses', 2, 'nassau', '{}'),
+    ('Nassau (Auto Press)', 'Nassau with a new press whenever a side goes 2 down', 1, 'nassau', '{"auto_press": true}'),
+    ('High-Low (Split)', 'Low ball vs low ball and high ball vs high ball, a point each', 2, 'high_low', '{"high_low_scoring": "split"}')
 ON CONFLICT (name) DO NOTHING;
@@ -1,33 +1,6 @@
 import React, { useState, useEffect } from 'react';
 import { Trophy, Users, User, Award, Clock, TrendingUp, TrendingDown, Minus } from 'lucide-react';
-import { apiClient, ApiError, Tournament, Team, Match } from '../services/api';
-
-interface TeamStanding {
-  team: Team;
-  points_won: number;
-  points_lost: number;
-  matches_won: number;
-  matches_lost: number;
-  matches_tied: number;
-  holes_won: number;
-  holes_lost: number;
-  holes_tied: number;
-}
-
-interface PlayerStanding {
-  user: { id: string; name: string; handicap?: number };
-  team: Team;
-  points_won: number;
-  points_lost: number;
-  matches_played: number;
-  matches_won: number;
-  matches_lost: number;
-  matches_tied: number;
-  holes_won: number;
-  holes_lost: number;
-  holes_tied: number;
-  win_percentage: number;
-}
+import { apiClient, ApiError, Tournament, Match, TeamStanding, PlayerStanding } from '../services/api';
 
 interface LeaderboardData {
   tournament: Tournament;
@@ -57,9 +30,6 @@ const TournamentLeaderboard = ({ tournamentId }: { tournamentId: string }) => {
       // Get tournament details
       const tournament = await apiClient.getTournament(tournamentId);
       
-      // Get teams
-      const teams = await apiClient.getTournamentTeams(tournamentId);
-      
       // Get rounds and matches for live match data
       const rounds = await apiClient.getTournamentRounds(tournamentId);
       const liveMatches: Match[] = [];
@@ -70,51 +40,12 @@ const TournamentLeaderboard = ({ tournamentId }: { tournamentId: string }) => {
         liveMatches.push(...matches.filter(m => m.status === 'in_progress'));
       }
 
-      // For now, create mock standings since we don't have player stats endpoint yet
-      // In a real app, you'd want to add a GET /api/v1/tournaments/:id/leaderboard endpoint
-      const teamStandings: TeamStanding[] = teams.map((team, index) => ({
-        team,
-        points_won: Math.random() * 15 + 5,
-        points_lost: Math.random() * 10,
-        matches_won: Math.floor(Math.random() * 8) + 1,
-        matches_lost: Math.floor(Math.random() * 6),
-        matches_tied: Math.floor(Math.random() * 3),
-        holes_won: Math.floor(Math.random() * 50) + 20,
-        holes_lost: Math.floor(Math.random() * 40) + 10,
-        holes_tied: Math.floor(Math.random() * 20) + 5,
-      }));
-
-      // Create mock individual standings
-      const individualStandings: PlayerStanding[] = [
-        {
-          user: { id: '1', name: 'John Doe', handicap: 12.5 },
-          team: teams[0] || { id: '1', name: 'Team 1', tournament_id: tournamentId, color: '#DC2626', created_at: '' },
-          points_won: 8.5,
-          points_lost: 3.5,
-          matches_played: 6,
-          matches_won: 5,
-          matches_lost: 1,
-          matches_tied: 0,
-          holes_won: 22,
-          holes_lost: 12,
-          holes_tied: 8,
-          win_percentage: 0.833
-        },
-        {
-          user: { id: '2', name: 'Jane Smith', handicap: 8.0 },
-          team: teams[0] || { id: '1', name: 'Team 1', tournament_id: tournamentId, color: '#DC2626', created_at: '' },
-          points_won: 7.0,
-          points_lost: 4.0,
-          matches_played: 5,
-          matches_won: 4,
-          matches_lost: 1,
-          matches_tied: 0,
-          holes_won: 18,
-          holes_lost: 14,
-          holes_tied: 6,
-          win_percentage: 0.8
-        }
-      ];
+      const [teamBoard, individualBoard] = await Promise.all([
+        apiClient.getTournamentLeaderboard(tournamentId, 'team'),
+        apiClient.getTournamentLeaderboard(tournamentId, 'individual'),
+      ]);
+      const teamStandings = teamBoard.team_standings ?? [];
+      const individualStandings = individualBoard.individual_standings ?? [];
 
       setData({
         tournament,
@@ -141,6 +72,7 @@ const TournamentLeaderboard = ({ tournamentId }: { tournamentId: string }) => {
         },
         team_standings: [
           {
+            rank: 1,
             team: { id: '1', name: 'Team USA', color: '#DC2626', tournament_id: 'demo', created_at: '2025-10-09' },
             points_won: 12.5,
             points_lost: 7.5,
@@ -152,6 +84,7 @@ const TournamentLeaderboard = ({ tournamentId }: { tournamentId: string }) => {
             holes_tied: 18
           },
           {
+            rank: 2,
             team: { id: '2', name: 'Team Europe', color: '#2563EB', tournament_id: 'demo', created_at: '2025-10-09' },
             points_won: 7.5,
             points_lost: 12.5,
@@ -165,6 +98,7 @@ const TournamentLeaderboard = ({ tournamentId }: { tournamentId: string }) => {
         ],
         individual_standings: [
           {
+            rank: 1,
             user: { id: '1', name: 'John Doe', handicap: 12.5 },
             team: { id: '1', name: 'Team USA', color: '#DC2626', tournament_id: 'demo', created_at: '2025-10-09' },
             points_won: 3.5,
@@ -387,12 +321,9 @@ const LiveMatchCard = ({ match }: { match: Match }) => {
       <div className="space-y-2 mb-3">
         <div className="flex items-center justify-between">
           <div className="font-medium">{match.team1?.name}</div>
-          <div className="font-bold">{match.team1_points}</div>
-        </div>
-        <div className="flex items-center justify-between">
           <div className="font-medium">{match.team2?.name}</div>
-          <div className="font-bold">{match.team2_points}</div>
         </div>
+        <div className="text-center font-bold">{match.result_text ?? 'A/S'}</div>
       </div>
 
       <div className="pt-3 border-t text-center">
@@ -34,6 +34,43 @@ export interface Team {
   created_at: string;
 }
 
+export interface TeamStanding {
+  rank: number;
+  team: Team;
+  points_won: number;
+  points_lost: number;
+  matches_won: number;
+  matches_lost: number;
+  matches_tied: number;
+  holes_won: number;
+  holes_lost: number;
+  holes_tied: number;
+}
+
+export interface PlayerStanding {
+  rank: number;
+  user: { id: string; name: string; handicap?: number };
+  team: Team;
+  points_won: number;
+  points_lost: number;
+  matches_played: number;
+  matches_won: number;
+  matches_lost: number;
+  matches_tied: number;
+  holes_won: number;
+  holes_lost: number;
+  holes_tied: number;
+  win_percentage: number;
+}
+
+export interface Leaderboard {
+  tournament_id: string;
+  view: 'team' | 'individual';
+  tiebreakers: string[];
+  team_standings?: TeamStanding[];
+  individual_standings?: PlayerStanding[];
+}
+
 export interface TeamMember {
   id: string;
   team_id: string;
@@ -75,6 +112,7 @@ export interface Match {
   points_available: number;
   team1_points: number;
   team2_points: number;
+  result_text?: string;
   team1?: Team;
   team2?: Team;
   format?: MatchFormat;
@@ -314,6 +352,10 @@ class ApiClient {
   }
 
   // Teams
+  async getTournamentLeaderboard(tournamentId: string, view: 'team' | 'individual' = 'team'): Promise<Leaderboard> {
+    return this.request<Leaderboard>(`/public/tournaments/${tournamentId}/leaderboard?view=${view}`);
+  }
+
   async getTournamentTeams(tournamentId: string): Promise<Team[]> {
     return this.request<Team[]>(`/public/tournaments/${tournamentId}/teams`);
   }
@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"mayhamapi/models"
+	"mayhamapi/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CourseHandler struct {
+	repo *repository.Repository
+}
+
+func NewCourseHandler(repo *repository.Repository) *CourseHandler {
+	return &CourseHandler{repo: repo}
+}
+
+// POST /api/v1/courses
+func (h *CourseHandler) CreateCourse(c *gin.Context) {
+	var req models.CreateCourseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	course, err := h.repo.CreateCourse(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, course)
+}
+
+// GET /api/v1/public/courses
+func (h *CourseHandler) ListCourses(c *gin.Context) {
+	courses, err := h.repo.ListCourses()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"courses": courses})
+}
+
+// GET /api/v1/public/courses/:course_id
+func (h *CourseHandler) GetCourse(c *gin.Context) {
+	course, err := h.repo.GetCourse(c.Param("course_id"))
+	if err != nil {
+		if err.Error() == "course not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, course)
+}
+
+// PUT /api/v1/courses/:course_id
+func (h *CourseHandler) UpdateCourse(c *gin.Context) {
+	var req models.CreateCourseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	course, err := h.repo.UpdateCourse(c.Param("course_id"), &req)
+	if err != nil {
+		if err.Error() == "course not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, course)
+}
+
+// DELETE /api/v1/courses/:course_id
+func (h *CourseHandler) DeleteCourse(c *gin.Context) {
+	if err := h.repo.DeleteCourse(c.Param("course_id")); err != nil {
+		if err.Error() == "course not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Course not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// POST /api/v1/courses/:course_id/tees
+func (h *CourseHandler) CreateTee(c *gin.Context) {
+	var req models.CreateTeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tee, err := h.repo.CreateTee(c.Param("course_id"), &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, tee)
+}
+
+// GET /api/v1/public/tees/:tee_id
+func (h *CourseHandler) GetTee(c *gin.Context) {
+	tee, err := h.repo.GetTee(c.Param("tee_id"))
+	if err != nil {
+		if err.Error() == "tee not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tee not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tee)
+}
+
+// PUT /api/v1/tees/:tee_id
+func (h *CourseHandler) UpdateTee(c *gin.Context) {
+	var req models.CreateTeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tee, err := h.repo.UpdateTee(c.Param("tee_id"), &req)
+	if err != nil {
+		if err.Error() == "tee not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tee not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tee)
+}
+
+// DELETE /api/v1/tees/:tee_id
+func (h *CourseHandler) DeleteTee(c *gin.Context) {
+	if err := h.repo.DeleteTee(c.Param("tee_id")); err != nil {
+		if err.Error() == "tee not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tee not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// PUT /api/v1/tees/:tee_id/holes
+func (h *CourseHandler) SetTeeHoles(c *gin.Context) {
+	var req models.SetTeeHolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateHoles(req.Holes); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	holes, err := h.repo.SetTeeHoles(c.Param("tee_id"), req.Holes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"holes": holes})
+}
+
+// POST /api/v1/courses/import
+// Accepts the JSON scorecard format (models.ImportCourseRequest), or CSV when the
+// Content-Type is text/csv. CSV imports take the course name from ?name= and have
+// one row per tee and hole:
+//
+//	tee,color,course_rating,slope,hole,par,stroke_index,yardage
+func (h *CourseHandler) ImportCourse(c *gin.Context) {
+	var req models.ImportCourseRequest
+	if strings.HasPrefix(c.ContentType(), "text/csv") {
+		parsed, err := parseScorecardCSV(c.Query("name"), c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		req = *parsed
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, tee := range req.Tees {
+		if err := validateHoles(tee.Holes); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("tee %q: %s", tee.Name, err.Error())})
+			return
+		}
+	}
+
+	course, err := h.repo.ImportCourse(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, course)
+}
+
+// validateHoles checks hole values are in range and that hole numbers and stroke
+// indexes are each used only once
+func validateHoles(holes []models.HoleRequest) error {
+	holeNumbers := make(map[int]bool)
+	strokeIndexes := make(map[int]bool)
+	for _, hole := range holes {
+		if hole.HoleNumber < 1 || hole.HoleNumber > 18 {
+			return fmt.Errorf("hole number %d must be between 1 and 18", hole.HoleNumber)
+		}
+		if hole.Par < 3 || hole.Par > 6 {
+			return fmt.Errorf("hole %d: par must be between 3 and 6", hole.HoleNumber)
+		}
+		if hole.StrokeIndex < 1 || hole.StrokeIndex > 18 {
+			return fmt.Errorf("hole %d: stroke index must be between 1 and 18", hole.HoleNumber)
+		}
+		if holeNumbers[hole.HoleNumber] {
+			return fmt.Errorf("hole %d appears more than once", hole.HoleNumber)
+		}
+		if strokeIndexes[hole.StrokeIndex] {
+			return fmt.Errorf("stroke index %d is used by more than one hole", hole.StrokeIndex)
+		}
+		holeNumbers[hole.HoleNumber] = true
+		strokeIndexes[hole.StrokeIndex] = true
+	}
+	return nil
+}
+
+func parseScorecardCSV(name string, body io.Reader) (*models.ImportCourseRequest, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, fmt.Errorf("course name is required (?name=)")
+	}
+
+	records, err := csv.NewReader(body).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("CSV must have a header row and at least one hole")
+	}
+
+	columns := make(map[string]int)
+	for i, header := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(header))] = i
+	}
+	for _, required := range []string{"tee", "hole", "par", "stroke_index"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV is missing the %q column", required)
+		}
+	}
+
+	field := func(record []string, column string) string {
+		if i, ok := columns[column]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	req := &models.ImportCourseRequest{Name: name}
+	teeIndex := make(map[string]int)
+	for line, record := range records[1:] {
+		teeName := field(record, "tee")
+		i, ok := teeIndex[teeName]
+		if !ok {
+			tee := models.ImportTeeRequest{CreateTeeRequest: models.CreateTeeRequest{Name: teeName}}
+			if color := field(record, "color"); color != "" {
+				tee.Color = &color
+			}
+			if rating, err := strconv.ParseFloat(field(record, "course_rating"), 64); err == nil {
+				tee.CourseRating = &rating
+			}
+			if slope, err := strconv.Atoi(field(record, "slope")); err == nil {
+				tee.Slope = &slope
+			}
+			req.Tees = append(req.Tees, tee)
+			i = len(req.Tees) - 1
+			teeIndex[teeName] = i
+		}
+
+		var hole models.HoleRequest
+		if hole.HoleNumber, err = strconv.Atoi(field(record, "hole")); err != nil {
+			return nil, fmt.Errorf("line %d: invalid hole number", line+2)
+		}
+		if hole.Par, err = strconv.Atoi(field(record, "par")); err != nil {
+			return nil, fmt.Errorf("line %d: invalid par", line+2)
+		}
+		if hole.StrokeIndex, err = strconv.Atoi(field(record, "stroke_index")); err != nil {
+			return nil, fmt.Errorf("line %d: invalid stroke index", line+2)
+		}
+		if yardage, err := strconv.Atoi(field(record, "yardage")); err == nil {
+			hole.Yardage = &yardage
+		}
+		req.Tees[i].Holes = append(req.Tees[i].Holes, hole)
+	}
+
+	return req, nil
+}
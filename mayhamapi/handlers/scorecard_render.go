@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+
+	"mayhamapi/scoring"
+)
+
+// scorecardGrid lays a scorecard out as printable rows: a header row of hole numbers, then
+// par, stroke index, one row per player (gross score, with a * per handicap stroke), each
+// team's format score and the running margin. The Out and In columns follow the last hole
+// played on each nine, the total follows the regulation holes and any playoff holes come
+// last. A "c" marks a conceded putt, or a conceded hole in the margin row.
+func scorecardGrid(card *scoring.Scorecard) [][]string {
+	type column struct {
+		hole  *scoring.ScorecardHole
+		total *scoring.ScorecardTotal
+	}
+	subtotals := make(map[string]*scoring.ScorecardTotal, len(card.Subtotals))
+	for i := range card.Subtotals {
+		subtotals[card.Subtotals[i].Label] = &card.Subtotals[i]
+	}
+	nine := func(hole *scoring.ScorecardHole) string {
+		if hole.HoleNumber <= 9 {
+			return "Out"
+		}
+		return "In"
+	}
+	lastOnNine := make(map[string]int)
+	for i := range card.Holes {
+		if !card.Holes[i].Playoff {
+			lastOnNine[nine(&card.Holes[i])] = i
+		}
+	}
+
+	var columns []column
+	for i := range card.Holes {
+		hole := &card.Holes[i]
+		if hole.Playoff {
+			continue
+		}
+		columns = append(columns, column{hole: hole})
+		if total, ok := subtotals[nine(hole)]; ok && lastOnNine[nine(hole)] == i {
+			columns = append(columns, column{total: total})
+		}
+	}
+	columns = append(columns, column{total: subtotals["Total"]})
+	for i := range card.Holes {
+		if card.Holes[i].Playoff {
+			columns = append(columns, column{hole: &card.Holes[i]})
+		}
+	}
+
+	optional := func(value *int) string {
+		if value == nil {
+			return ""
+		}
+		return strconv.Itoa(*value)
+	}
+	row := func(label string, cell func(column) string) []string {
+		cells := []string{label}
+		for _, col := range columns {
+			cells = append(cells, cell(col))
+		}
+		return cells
+	}
+
+	grid := [][]string{
+		row("Hole", func(col column) string {
+			if col.total != nil {
+				return col.total.Label
+			}
+			return strconv.Itoa(col.hole.HoleNumber)
+		}),
+		row("Par", func(col column) string {
+			if col.total != nil {
+				return optional(col.total.Par)
+			}
+			return optional(col.hole.Par)
+		}),
+		row("SI", func(col column) string {
+			if col.total != nil {
+				return ""
+			}
+			return optional(col.hole.StrokeIndex)
+		}),
+	}
+
+	players := append(append([]scoring.ScorecardPlayer{}, card.Team1.Players...), card.Team2.Players...)
+	for i, player := range players {
+		label := player.Name
+		if player.PlayingHandicap != nil {
+			label = fmt.Sprintf("%s (%d)", player.Name, *player.PlayingHandicap)
+		}
+		grid = append(grid, row(label, func(col column) string {
+			if col.total != nil {
+				sum := col.total.Players[i]
+				if sum.Net != sum.Gross {
+					return fmt.Sprintf("%d/%d", sum.Gross, sum.Net)
+				}
+				return strconv.Itoa(sum.Gross)
+			}
+			box := col.hole.Scores[i]
+			cell := optional(box.Gross) + strings.Repeat("*", max(box.Strokes, 0))
+			if box.Conceded {
+				cell += "c"
+			}
+			return cell
+		}))
+	}
+
+	for _, team := range []struct {
+		name  string
+		score func(*scoring.ScorecardHole) *int
+	}{
+		{card.Team1.Name, func(hole *scoring.ScorecardHole) *int { return hole.Team1Score }},
+		{card.Team2.Name, func(hole *scoring.ScorecardHole) *int { return hole.Team2Score }},
+	} {
+		grid = append(grid, row(team.name, func(col column) string {
+			if col.total != nil {
+				return ""
+			}
+			return optional(team.score(col.hole))
+		}))
+	}
+
+	grid = append(grid, row("Match ("+card.Team1.Name+")", func(col column) string {
+		margin := ""
+		switch {
+		case col.total != nil && col.total.Label == "Total":
+			return card.ResultText
+		case col.total != nil || col.hole.Team1Up == nil:
+			return ""
+		case *col.hole.Team1Up == 0:
+			margin = "AS"
+		default:
+			margin = fmt.Sprintf("%+d", *col.hole.Team1Up)
+		}
+		if col.hole.ConcededByTeamID != nil {
+			margin += "c"
+		}
+		return margin
+	}))
+
+	return grid
+}
+
+// writeScorecardText renders the scorecard as fixed-width plain text
+func writeScorecardText(w io.Writer, card *scoring.Scorecard) error {
+	grid := scorecardGrid(card)
+
+	widths := make([]int, len(grid[0]))
+	for _, cells := range grid {
+		for i, cell := range cells {
+			widths[i] = max(widths[i], len([]rune(cell)))
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, "Match %d: %s vs %s (%s)\n\n", card.MatchNumber, card.Team1.Name, card.Team2.Name, card.Format); err != nil {
+		return err
+	}
+	for _, cells := range grid {
+		line := make([]string, len(cells))
+		for i, cell := range cells {
+			if i == 0 {
+				line[i] = fmt.Sprintf("%-*s", widths[i], cell)
+			} else {
+				line[i] = fmt.Sprintf("%*s", widths[i], cell)
+			}
+		}
+		if _, err := fmt.Fprintln(w, strings.TrimRight(strings.Join(line, "  "), " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var scorecardHTML = template.Must(template.New("scorecard").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Match {{.Card.MatchNumber}}: {{.Card.Team1.Name}} vs {{.Card.Team2.Name}}</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #999; padding: 2px 6px; text-align: center; }
+th:first-child, td:first-child { text-align: left; }
+@media print { body { margin: 0; } }
+</style>
+</head>
+<body>
+<h1>Match {{.Card.MatchNumber}}: {{.Card.Team1.Name}} vs {{.Card.Team2.Name}}</h1>
+<p>{{.Card.Format}}{{if .Card.ResultText}} &middot; {{.Card.ResultText}}{{end}}</p>
+<table>
+<thead><tr>{{range index .Grid 0}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>
+{{range slice .Grid 1}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+</body>
+</html>
+`))
+
+// writeScorecardHTML renders the scorecard as a printable HTML page
+func writeScorecardHTML(w io.Writer, card *scoring.Scorecard) error {
+	return scorecardHTML.Execute(w, struct {
+		Card *scoring.Scorecard
+		Grid [][]string
+	}{card, scorecardGrid(card)})
+}
@@ -1,12 +1,15 @@
 package handlers
 
 import (
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"mayhamapi/models"
 	"mayhamapi/repository"
 	"mayhamapi/scoring"
+	"mayhamapi/websocket"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,12 +17,14 @@ import (
 type ScoringHandler struct {
 	repo           *repository.Repository
 	scoringService *scoring.ScoringService
+	wsHub          *websocket.Hub
 }
 
-func NewScoringHandler(repo *repository.Repository, scoringService *scoring.ScoringService) *ScoringHandler {
+func NewScoringHandler(repo *repository.Repository, scoringService *scoring.ScoringService, wsHub *websocket.Hub) *ScoringHandler {
 	return &ScoringHandler{
 		repo:           repo,
 		scoringService: scoringService,
+		wsHub:          wsHub,
 	}
 }
 
@@ -33,82 +38,565 @@ func (h *ScoringHandler) SubmitScores(c *gin.Context) {
 		return
 	}
 
-	// Submit each score to the database
+	adjusted, ok := h.validateScores(c, matchID, req.Scores)
+	if !ok {
+		return
+	}
+
+	// Write the scores and the recalculated match result in one transaction
 	var submittedScores []models.Score
-	for _, holeScore := range req.Scores {
-		score, err := h.repo.SubmitScore(matchID, holeScore.UserID, holeScore.HoleNumber, holeScore.Strokes)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
+	var matchStatus *scoring.MatchStatus
+	err := h.repo.WithTx(func(tx *repository.Repository) error {
+		for i, holeScore := range req.Scores {
+			score, err := tx.SubmitScore(matchID, holeScore.UserID, holeScore.HoleNumber, holeScore.Strokes, &adjusted[i])
+			if err != nil {
+				return err
+			}
+			submittedScores = append(submittedScores, *score)
+
+			err = tx.RecordMatchEvent(matchID, models.EventScoreSubmitted, &score.HoleNumber, &score.UserID, currentUserID(c),
+				gin.H{"strokes": score.Strokes, "adjusted_strokes": score.AdjustedStrokes})
+			if err != nil {
+				return err
+			}
 		}
-		submittedScores = append(submittedScores, *score)
+
+		var err error
+		matchStatus, err = h.recalculateMatch(tx, matchID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if matchStatus.MatchComplete {
+		h.broadcastCupClinch(matchID)
 	}
 
-	// Get match details
+	c.JSON(http.StatusOK, gin.H{
+		"scores":       submittedScores,
+		"match_status": matchStatus,
+	})
+}
+
+// validateScores checks the scores against the match and returns their adjusted strokes.
+// It writes the error response and returns false when the match is missing or any score
+// is invalid; invalid scores are listed individually under score_errors.
+func (h *ScoringHandler) validateScores(c *gin.Context, matchID string, scores []models.HoleScore) ([]int, bool) {
 	match, err := h.repo.GetMatch(matchID)
 	if err != nil {
+		if err.Error() == "match not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
+			return nil, false
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	adjusted, scoreErrors, err := h.scoringService.ValidateScores(match, scores)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if len(scoreErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid scores", "score_errors": scoreErrors})
+		return nil, false
+	}
+
+	return adjusted, true
+}
+
+// currentUserID returns the authenticated user's ID, or nil for anonymous requests
+func currentUserID(c *gin.Context) *string {
+	if userID := c.GetString("userID"); userID != "" {
+		return &userID
+	}
+	return nil
+}
+
+// recalculateMatch scores the match from its stored scores, concessions and presses and saves the
+// result, reading and writing everything through the given repository so a transaction sees its own writes
+func (h *ScoringHandler) recalculateMatch(repo *repository.Repository, matchID string) (*scoring.MatchStatus, error) {
+	service := h.scoringService.WithRepo(repo)
+
+	match, err := repo.GetMatch(matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	scores, err := repo.GetMatchScores(matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	matchStatus, err := service.CalculateMatchStatus(match, scores)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := service.SaveMatchResult(repo, match, matchStatus); err != nil {
+		return nil, err
+	}
+
+	return matchStatus, nil
+}
+
+// broadcastCupClinch sends cup_clinched to the tournament's WebSocket clients when the match
+// that just finished is the one that decided the cup. The scores are already saved, so a
+// failure here is only logged.
+func (h *ScoringHandler) broadcastCupClinch(matchID string) {
+	cup, err := h.scoringService.ClinchedByMatch(matchID)
+	if err != nil {
+		log.Printf("Failed to check cup clinch after match %s: %v", matchID, err)
 		return
 	}
+	if cup != nil {
+		h.wsHub.BroadcastToTournament(cup.TournamentID, "cup_clinched", cup)
+	}
+}
+
+// GET /api/v1/matches/:match_id/scores
+func (h *ScoringHandler) GetMatchScores(c *gin.Context) {
+	matchID := c.Param("match_id")
 
-	// Calculate match status using scoring service
 	scores, err := h.repo.GetMatchScores(matchID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	// Get match details for context
+	match, err := h.repo.GetMatch(matchID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Calculate current match status
 	matchStatus, err := h.scoringService.CalculateMatchStatus(match, scores)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Update match if complete
-	if matchStatus.MatchComplete {
-		err = h.repo.UpdateMatchStatus(matchID, "completed")
-		if err != nil {
+	c.JSON(http.StatusOK, gin.H{
+		"scores":       scores,
+		"match_status": matchStatus,
+	})
+}
+
+// GET /api/v1/rounds/:round_id/rankings
+func (h *ScoringHandler) GetRoundRankings(c *gin.Context) {
+	roundID := c.Param("round_id")
+
+	rankings, err := h.scoringService.CalculateRoundRankings(roundID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rankings)
+}
+
+// GET /api/v1/tournaments/:tournament_id/cup
+func (h *ScoringHandler) GetCupStatus(c *gin.Context) {
+	cup, err := h.scoringService.CalculateCupStatus(c.Param("tournament_id"))
+	if err != nil {
+		switch err.Error() {
+		case "tournament not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tournament not found"})
+		case "cup needs exactly two teams":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Cup standings need a tournament with exactly two teams"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, cup)
+}
+
+// GET /api/v1/tournaments/:tournament_id/leaderboard?view=team|individual&tiebreakers=points,holes_diff
+func (h *ScoringHandler) GetLeaderboard(c *gin.Context) {
+	view := c.DefaultQuery("view", "team")
+	if view != "team" && view != "individual" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "view must be team or individual"})
+		return
+	}
+
+	var tiebreakers []string
+	if param := c.Query("tiebreakers"); param != "" {
+		tiebreakers = strings.Split(param, ",")
+	}
+
+	leaderboard, err := h.scoringService.CalculateLeaderboard(c.Param("tournament_id"), view, tiebreakers)
+	if err != nil {
+		switch {
+		case err.Error() == "tournament not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tournament not found"})
+		case strings.HasPrefix(err.Error(), "unknown tiebreaker"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, leaderboard)
+}
+
+// GET /api/v1/tournaments/:tournament_id/live
+func (h *ScoringHandler) GetLiveFeed(c *gin.Context) {
+	tournamentID := c.Param("tournament_id")
+
+	if _, err := h.repo.GetTournament(tournamentID); err != nil {
+		if err.Error() == "tournament not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tournament not found"})
 			return
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"scores":       submittedScores,
-		"match_status": matchStatus,
-	})
+	matches, err := h.repo.GetLiveMatches(tournamentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Polled by every screen and spectator, so let browsers and the proxy share a response briefly
+	c.Header("Cache-Control", "public, max-age=5")
+	c.JSON(http.StatusOK, models.LiveFeed{TournamentID: tournamentID, ActiveMatches: matches})
 }
 
-// GET /api/v1/matches/:match_id/scores
-func (h *ScoringHandler) GetMatchScores(c *gin.Context) {
+// GET /api/v1/users/:user_id/stats?tournament_id=...
+func (h *ScoringHandler) GetUserStats(c *gin.Context) {
+	var tournamentID *string
+	if id := c.Query("tournament_id"); id != "" {
+		tournamentID = &id
+	}
+
+	viewerID := currentUserID(c)
+	if viewerID == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	stats, err := h.scoringService.CalculateCareerStats(c.Param("user_id"), *viewerID, tournamentID)
+	if err != nil {
+		switch err.Error() {
+		case "user not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		case "tournament not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tournament not found"})
+		case "not a member of the tournament's group":
+			c.JSON(http.StatusForbidden, gin.H{"error": "You must be a member of this tournament's group to view its stats"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// POST /api/v1/matches/:match_id/presses
+func (h *ScoringHandler) CreatePress(c *gin.Context) {
 	matchID := c.Param("match_id")
 
-	scores, err := h.repo.GetMatchScores(matchID)
+	var req models.CreatePressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	match, err := h.repo.GetMatch(matchID)
 	if err != nil {
+		if err.Error() == "match not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Get match details for context
+	if err := h.scoringService.ValidatePress(match, req.TeamID, req.StartHole); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Store the press and the recalculated match result in one transaction
+	var press *models.Press
+	var matchStatus *scoring.MatchStatus
+	err = h.repo.WithTx(func(tx *repository.Repository) error {
+		var err error
+		if press, err = tx.CreatePress(matchID, req.TeamID, req.StartHole); err != nil {
+			return err
+		}
+		matchStatus, err = h.recalculateMatch(tx, matchID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"press":        press,
+		"match_status": matchStatus,
+	})
+}
+
+// POST /api/v1/matches/:match_id/concessions
+func (h *ScoringHandler) CreateConcession(c *gin.Context) {
+	matchID := c.Param("match_id")
+
+	var req models.CreateConcessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	match, err := h.repo.GetMatch(matchID)
 	if err != nil {
+		if err.Error() == "match not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Calculate current match status
-	matchStatus, err := h.scoringService.CalculateMatchStatus(match, scores)
+	concession, err := h.scoringService.NewConcession(match, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	concession.RecordedBy = currentUserID(c)
+
+	// Store the concession, its audit event and the recalculated match result in one transaction
+	var matchStatus *scoring.MatchStatus
+	err = h.repo.WithTx(func(tx *repository.Repository) error {
+		var err error
+		concession, err = tx.CreateConcession(concession)
+		if err != nil {
+			return err
+		}
+		err = tx.RecordMatchEvent(matchID, models.EventConcession, concession.HoleNumber, concession.UserID, concession.RecordedBy, concession)
+		if err != nil {
+			return err
+		}
+		matchStatus, err = h.recalculateMatch(tx, matchID)
+		return err
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"scores":       scores,
+	if matchStatus.MatchComplete {
+		h.broadcastCupClinch(matchID)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"concession":   concession,
 		"match_status": matchStatus,
 	})
 }
 
+// GET /api/v1/matches/:match_id/audit
+func (h *ScoringHandler) GetMatchAudit(c *gin.Context) {
+	events, err := h.repo.GetMatchEvents(c.Param("match_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"events": events})
+}
+
+// GET /api/v1/matches/:match_id/scorecard?format=json|text|html
+func (h *ScoringHandler) GetScorecard(c *gin.Context) {
+	match, err := h.repo.GetMatch(c.Param("match_id"))
+	if err != nil {
+		if err.Error() == "match not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	card, err := h.scoringService.BuildScorecard(match)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		c.JSON(http.StatusOK, card)
+	case "text":
+		c.Header("Content-Type", "text/plain; charset=utf-8")
+		c.Status(http.StatusOK)
+		if err := writeScorecardText(c.Writer, card); err != nil {
+			log.Printf("failed to write scorecard for match %s: %v", match.ID, err)
+		}
+	case "html":
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		c.Status(http.StatusOK)
+		if err := writeScorecardHTML(c.Writer, card); err != nil {
+			log.Printf("failed to write scorecard for match %s: %v", match.ID, err)
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json, text or html"})
+	}
+}
+
+// GET /api/v1/rounds/:round_id/skins
+func (h *ScoringHandler) GetRoundSkins(c *gin.Context) {
+	roundID := c.Param("round_id")
+
+	if _, err := h.repo.GetRound(roundID); err != nil {
+		if err.Error() == "round not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Round not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	skins, err := h.scoringService.CalculateSkins(roundID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, skins)
+}
+
+// PUT /api/v1/rounds/:round_id/skins
+func (h *ScoringHandler) SetRoundSkins(c *gin.Context) {
+	roundID := c.Param("round_id")
+
+	var req models.SetSkinsGameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := h.repo.GetRound(roundID); err != nil {
+		if err.Error() == "round not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Round not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	game, err := h.repo.SaveSkinsGame(roundID, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, game)
+}
+
+// PUT /api/v1/matches/:match_id/points
+func (h *ScoringHandler) SetMatchPoints(c *gin.Context) {
+	matchID := c.Param("match_id")
+
+	var req models.SetMatchPointsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validatePointValue("points_available", req.PointsAvailable); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Rescore so a finished match's team points reflect the new value
+	var matchStatus *scoring.MatchStatus
+	err := h.repo.WithTx(func(tx *repository.Repository) error {
+		if err := tx.SetMatchPoints(matchID, req.PointsAvailable); err != nil {
+			return err
+		}
+
+		var err error
+		matchStatus, err = h.recalculateMatch(tx, matchID)
+		return err
+	})
+	if err != nil {
+		if err.Error() == "match not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if matchStatus.MatchComplete {
+		h.broadcastCupClinch(matchID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"match_status": matchStatus})
+}
+
+// POST /api/v1/admin/recompute?tournament_id=...&dry_run=true
+// Rescores matches from their stored scores: one tournament's, or every match when no
+// tournament_id is given. A dry run only reports the results that would change.
+func (h *ScoringHandler) RecomputeResults(c *gin.Context) {
+	var tournamentID *string
+	if id := c.Query("tournament_id"); id != "" {
+		tournamentID = &id
+	}
+
+	dryRun := false
+	if value := c.Query("dry_run"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dry_run value"})
+			return
+		}
+	}
+
+	report, err := h.scoringService.Recompute(tournamentID, dryRun)
+	if err != nil {
+		if err.Error() == "tournament not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tournament not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// POST /api/v1/admin/rebuild-stats?tournament_id=...
+func (h *ScoringHandler) RebuildPlayerStats(c *gin.Context) {
+	var tournamentID *string
+	if id := c.Query("tournament_id"); id != "" {
+		tournamentID = &id
+	}
+
+	rebuild, err := h.scoringService.RebuildPlayerStats(tournamentID)
+	if err != nil {
+		if err.Error() == "tournament not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tournament not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rebuild)
+}
+
 // PATCH /api/v1/matches/:match_id/scores/:hole_number
 func (h *ScoringHandler) UpdateHoleScore(c *gin.Context) {
 	matchID := c.Param("match_id")
@@ -126,36 +614,47 @@ func (h *ScoringHandler) UpdateHoleScore(c *gin.Context) {
 		return
 	}
 
-	// Update scores for the specific hole
-	var updatedScores []models.Score
+	// Only scores for the hole in the URL are updated
+	var holeScores []models.HoleScore
 	for _, holeScore := range req.Scores {
 		if holeScore.HoleNumber == holeNumber {
-			score, err := h.repo.SubmitScore(matchID, holeScore.UserID, holeScore.HoleNumber, holeScore.Strokes)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-				return
-			}
-			updatedScores = append(updatedScores, *score)
+			holeScores = append(holeScores, holeScore)
 		}
 	}
 
-	// Get updated match status
-	match, err := h.repo.GetMatch(matchID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	adjusted, ok := h.validateScores(c, matchID, holeScores)
+	if !ok {
 		return
 	}
 
-	allScores, err := h.repo.GetMatchScores(matchID)
+	var updatedScores []models.Score
+	var matchStatus *scoring.MatchStatus
+	err = h.repo.WithTx(func(tx *repository.Repository) error {
+		for i, holeScore := range holeScores {
+			score, err := tx.SubmitScore(matchID, holeScore.UserID, holeScore.HoleNumber, holeScore.Strokes, &adjusted[i])
+			if err != nil {
+				return err
+			}
+			updatedScores = append(updatedScores, *score)
+
+			err = tx.RecordMatchEvent(matchID, models.EventScoreUpdated, &score.HoleNumber, &score.UserID, currentUserID(c),
+				gin.H{"strokes": score.Strokes, "adjusted_strokes": score.AdjustedStrokes})
+			if err != nil {
+				return err
+			}
+		}
+
+		var err error
+		matchStatus, err = h.recalculateMatch(tx, matchID)
+		return err
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	matchStatus, err := h.scoringService.CalculateMatchStatus(match, allScores)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+	if matchStatus.MatchComplete {
+		h.broadcastCupClinch(matchID)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
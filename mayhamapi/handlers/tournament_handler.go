@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"fmt"
+	"math"
 	"net/http"
+	"strings"
 
 	"mayhamapi/models"
 	"mayhamapi/repository"
+	"mayhamapi/scoring"
 
 	"github.com/gin-gonic/gin"
 )
@@ -107,6 +111,50 @@ func (h *TournamentHandler) GetTeams(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"teams": teams})
 }
 
+// PUT /api/v1/tournaments/:tournament_id/cup
+func (h *TournamentHandler) SetTournamentCup(c *gin.Context) {
+	tournamentID := c.Param("tournament_id")
+
+	var req models.SetCupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.TargetPoints != nil && *req.TargetPoints <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "target_points must be greater than 0"})
+		return
+	}
+
+	if req.DefendingTeamID != nil {
+		teams, err := h.repo.GetTeamsByTournament(tournamentID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		found := false
+		for _, team := range teams {
+			found = found || team.ID == *req.DefendingTeamID
+		}
+		if !found {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "defending_team_id is not a team in this tournament"})
+			return
+		}
+	}
+
+	tournament, err := h.repo.SetTournamentCup(tournamentID, &req)
+	if err != nil {
+		if err.Error() == "tournament not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tournament not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tournament)
+}
+
 // POST /api/v1/teams/:team_id/members
 func (h *TournamentHandler) AddTeamMember(c *gin.Context) {
 	teamID := c.Param("team_id")
@@ -136,6 +184,13 @@ func (h *TournamentHandler) CreateRound(c *gin.Context) {
 		return
 	}
 
+	if req.PointsPerMatch != nil {
+		if err := validatePointValue("points_per_match", *req.PointsPerMatch); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	round, err := h.repo.CreateRound(tournamentID, &req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -158,6 +213,29 @@ func (h *TournamentHandler) GetRounds(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"rounds": rounds})
 }
 
+// PUT /api/v1/rounds/:round_id/course
+func (h *TournamentHandler) SetRoundCourse(c *gin.Context) {
+	roundID := c.Param("round_id")
+
+	var req models.SetRoundCourseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	round, err := h.repo.SetRoundCourse(roundID, req.CourseID, req.TeeID)
+	if err != nil {
+		if err.Error() == "round or tee not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Round not found, or tee does not belong to the course"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, round)
+}
+
 // POST /api/v1/rounds/:round_id/matches
 func (h *TournamentHandler) CreateMatch(c *gin.Context) {
 	roundID := c.Param("round_id")
@@ -168,7 +246,53 @@ func (h *TournamentHandler) CreateMatch(c *gin.Context) {
 		return
 	}
 
-	match, err := h.repo.CreateMatch(roundID, &req)
+	if err := validateHoleSequence(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	courseHoles, err := h.repo.GetRoundCourseHoles(roundID)
+	if err != nil {
+		if err.Error() == "round not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Round not found"})
+			return
+		}
+		if err.Error() == "round tee has no holes" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "The round's tee has no holes; add its scorecard before creating matches"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateCourseHoles(&req, courseHoles); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.PointsAvailable != nil {
+		if err := validatePointValue("points_available", *req.PointsAvailable); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	problem, err := h.checkMatchRoster(&req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if problem != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": problem})
+		return
+	}
+
+	// Create the match and its players together so a failure leaves no partial roster
+	var match *models.Match
+	err = h.repo.WithTx(func(tx *repository.Repository) error {
+		var err error
+		match, err = tx.CreateMatch(roundID, &req)
+		return err
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -217,3 +341,138 @@ func (h *TournamentHandler) GetMatchFormats(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"formats": formats})
 }
+
+// POST /api/v1/match-formats
+// Built-in scoring types can be added with their own config; scoring_type "custom"
+// defines the format entirely through config.contests.
+func (h *TournamentHandler) CreateMatchFormat(c *gin.Context) {
+	var req models.CreateMatchFormatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	definition := &models.MatchFormatDefinition{
+		Name:           req.Name,
+		PlayersPerSide: req.PlayersPerSide,
+		ScoringType:    req.ScoringType,
+		Config:         req.Config,
+	}
+	if err := scoring.ValidateFormat(definition); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	format, err := h.repo.CreateMatchFormat(&req)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			c.JSON(http.StatusConflict, gin.H{"error": "A match format with this name already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, format)
+}
+
+// validateHoleSequence checks an explicit hole sequence covers the match's length with
+// no repeated holes, and agrees with the starting hole when both are given
+func validateHoleSequence(req *models.CreateMatchRequest) error {
+	if len(req.HoleSequence) == 0 {
+		return nil
+	}
+	if len(req.HoleSequence) != req.Holes {
+		return fmt.Errorf("hole_sequence has %d holes but the match is %d holes", len(req.HoleSequence), req.Holes)
+	}
+	if req.StartingHole != 0 && req.StartingHole != req.HoleSequence[0] {
+		return fmt.Errorf("starting_hole %d doesn't match the first hole in hole_sequence", req.StartingHole)
+	}
+
+	seen := make(map[int]bool)
+	for _, hole := range req.HoleSequence {
+		if seen[hole] {
+			return fmt.Errorf("hole %d appears more than once in hole_sequence", hole)
+		}
+		seen[hole] = true
+	}
+	return nil
+}
+
+// validateCourseHoles checks the match's holes all exist on a course with courseHoles holes
+func validateCourseHoles(req *models.CreateMatchRequest, courseHoles int) error {
+	if req.Holes > courseHoles {
+		return fmt.Errorf("a %d-hole match doesn't fit on a %d-hole course", req.Holes, courseHoles)
+	}
+	if req.StartingHole > courseHoles {
+		return fmt.Errorf("starting_hole %d is past the course's last hole", req.StartingHole)
+	}
+	for _, hole := range req.HoleSequence {
+		if hole > courseHoles {
+			return fmt.Errorf("hole %d in hole_sequence is past the course's last hole", hole)
+		}
+	}
+	return nil
+}
+
+// checkMatchRoster checks the match's format exists and that any listed players are on their side's
+// team, once each, and fill the format's players_per_side. It returns a message for the client when
+// the roster is invalid, and an error when the check itself fails.
+func (h *TournamentHandler) checkMatchRoster(req *models.CreateMatchRequest) (string, error) {
+	format, err := h.repo.GetMatchFormat(req.MatchFormatID)
+	if err != nil {
+		if err.Error() == "match format not found" {
+			return "Match format not found", nil
+		}
+		return "", err
+	}
+	if req.Team1ID == req.Team2ID {
+		return "team1_id and team2_id must be different teams", nil
+	}
+
+	seen := make(map[string]bool)
+	sides := []struct {
+		field   string
+		teamID  string
+		players []string
+	}{
+		{"team1_players", req.Team1ID, req.Team1Players},
+		{"team2_players", req.Team2ID, req.Team2Players},
+	}
+	for _, side := range sides {
+		if len(side.players) == 0 {
+			continue
+		}
+		if len(side.players) != format.PlayersPerSide {
+			return fmt.Sprintf("%s lists %d players but %s is played %d a side", side.field, len(side.players), format.Name, format.PlayersPerSide), nil
+		}
+
+		members, err := h.repo.GetTeamMembers(side.teamID)
+		if err != nil {
+			return "", err
+		}
+		onTeam := make(map[string]bool)
+		for _, member := range members {
+			onTeam[member.UserID] = true
+		}
+		for _, userID := range side.players {
+			if !onTeam[userID] {
+				return fmt.Sprintf("%s: user %s is not on the team", side.field, userID), nil
+			}
+			if seen[userID] {
+				return fmt.Sprintf("%s: user %s is listed more than once", side.field, userID), nil
+			}
+			seen[userID] = true
+		}
+	}
+	return "", nil
+}
+
+// validatePointValue checks a match's point value fits the points columns: positive, below 100,
+// and with at most one decimal place so a halved match splits into exact shares
+func validatePointValue(field string, points float64) error {
+	if points <= 0 || points >= 100 || math.Abs(points*10-math.Round(points*10)) > 1e-9 {
+		return fmt.Errorf("%s must be greater than 0 and below 100, with at most one decimal place", field)
+	}
+	return nil
+}
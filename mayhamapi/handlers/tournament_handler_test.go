@@ -0,0 +1,29 @@
+package handlers
+
+import "testing"
+
+func TestValidatePointValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		points  float64
+		wantErr bool
+	}{
+		{"one point", 1, false},
+		{"half point", 0.5, false},
+		{"final-day singles", 2, false},
+		{"one decimal place", 1.5, false},
+		{"largest", 99.9, false},
+		{"zero", 0, true},
+		{"negative", -1, true},
+		{"too large", 100, true},
+		{"two decimal places", 1.25, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validatePointValue("points_available", tt.points); (err != nil) != tt.wantErr {
+				t.Errorf("validatePointValue(%v) error = %v, want error %v", tt.points, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,459 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"mayhamapi/models"
+	"mayhamapi/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// setupPlan is a validated setup request with its emails, formats and team names resolved.
+// result describes what will be created, and gets its IDs filled in as it is.
+type setupPlan struct {
+	teams  []setupTeamPlan
+	rounds []setupRoundPlan
+	result models.SetupCreation
+}
+
+type setupTeamPlan struct {
+	req   models.CreateTeamRequest
+	users []*models.User
+}
+
+type setupRoundPlan struct {
+	req     models.CreateRoundRequest
+	matches []setupMatchPlan
+}
+
+// setupMatchPlan refers to its teams and players by index, as their IDs don't exist until the teams are created
+type setupMatchPlan struct {
+	req                        models.CreateMatchRequest
+	team1, team2               int
+	team1Players, team2Players []int
+}
+
+// POST /api/v1/tournaments/:tournament_id/setup?dry_run=true
+// Creates teams with their rosters, rounds and matches in one transaction. The whole request is
+// validated first and every problem is listed under setup_errors by its path in the request;
+// a dry run stops after validation and reports what would be created.
+func (h *TournamentHandler) SetupTournament(c *gin.Context) {
+	tournamentID := c.Param("tournament_id")
+
+	dryRun := false
+	if value := c.Query("dry_run"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dry_run value"})
+			return
+		}
+	}
+
+	tournament, err := h.repo.GetTournament(tournamentID)
+	if err != nil {
+		if err.Error() == "tournament not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tournament not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var req models.TournamentSetupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan, setupErrors, err := h.planSetup(tournamentID, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if len(setupErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid setup", "setup_errors": setupErrors})
+		return
+	}
+
+	status := http.StatusOK
+	if !dryRun {
+		err := h.repo.WithTx(func(tx *repository.Repository) error {
+			return createSetup(tx, tournamentID, plan)
+		})
+		if err != nil {
+			if strings.Contains(err.Error(), "duplicate key") {
+				c.JSON(http.StatusConflict, gin.H{"error": "A round number or team member in the setup was added to the tournament meanwhile"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		status = http.StatusCreated
+	}
+
+	c.JSON(status, models.TournamentSetupResult{Tournament: tournament, DryRun: dryRun, Created: plan.result})
+}
+
+// planSetup checks a setup request against itself and the tournament as it stands, and resolves
+// it into a plan. Problems with the request come back as setup errors rather than an error.
+func (h *TournamentHandler) planSetup(tournamentID string, req *models.TournamentSetupRequest) (*setupPlan, []models.SetupError, error) {
+	setupErrors := []models.SetupError{}
+	fail := func(path, format string, args ...interface{}) {
+		setupErrors = append(setupErrors, models.SetupError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(req.Teams) == 0 && len(req.Rounds) == 0 {
+		fail("", "setup needs at least one team or round")
+		return nil, setupErrors, nil
+	}
+
+	// Teams and players already in the tournament
+	existingTeams, err := h.repo.GetTeamsByTournament(tournamentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	teamNames := make(map[string]bool)
+	rostered := make(map[string]string) // user ID -> team name
+	for _, team := range existingTeams {
+		teamNames[team.Name] = true
+		members, err := h.repo.GetTeamMembers(team.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, member := range members {
+			rostered[member.UserID] = team.Name
+		}
+	}
+
+	plan := &setupPlan{result: models.SetupCreation{Teams: []models.SetupTeam{}, Rounds: []models.SetupRound{}}}
+	teamIndex := make(map[string]int)
+	for i, team := range req.Teams {
+		path := fmt.Sprintf("teams[%d]", i)
+		if err := binding.Validator.ValidateStruct(&team.CreateTeamRequest); err != nil {
+			fail(path, "%s", err.Error())
+		}
+		if _, ok := teamIndex[team.Name]; ok {
+			fail(path+".name", "team %q appears more than once in the setup", team.Name)
+		} else if teamNames[team.Name] {
+			fail(path+".name", "the tournament already has a team named %q", team.Name)
+		} else {
+			teamIndex[team.Name] = i
+		}
+
+		planned := setupTeamPlan{req: team.CreateTeamRequest}
+		summary := models.SetupTeam{Name: team.Name, Color: team.Color, Players: []models.PlayerSummary{}}
+		for j, email := range team.Players {
+			playerPath := fmt.Sprintf("%s.players[%d]", path, j)
+			user, err := h.repo.GetUserByEmail(email)
+			if err != nil {
+				if err.Error() == "user not found" {
+					fail(playerPath, "no user has the email %q", email)
+					planned.users = append(planned.users, nil)
+					continue
+				}
+				return nil, nil, err
+			}
+			if teamName, ok := rostered[user.ID]; ok {
+				fail(playerPath, "%s is already on %s", user.Name, teamName)
+			}
+			rostered[user.ID] = team.Name
+			planned.users = append(planned.users, user)
+			summary.Players = append(summary.Players, models.PlayerSummary{ID: user.ID, Name: user.Name, Handicap: user.Handicap})
+		}
+
+		plan.teams = append(plan.teams, planned)
+		plan.result.Teams = append(plan.result.Teams, summary)
+	}
+
+	existingRounds, err := h.repo.GetRoundsByTournament(tournamentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	roundNumbers := make(map[int]bool)
+	for _, round := range existingRounds {
+		roundNumbers[round.RoundNumber] = true
+	}
+	setupRounds := make(map[int]bool)
+
+	formats := make(map[string]*models.MatchFormatDefinition) // by name; nil for unknown names
+	lookupFormat := func(name string) (*models.MatchFormatDefinition, error) {
+		if format, ok := formats[name]; ok {
+			return format, nil
+		}
+		format, err := h.repo.GetMatchFormatByName(name)
+		if err != nil && err.Error() != "match format not found" {
+			return nil, err
+		}
+		formats[name] = format
+		return format, nil
+	}
+
+	for i, round := range req.Rounds {
+		path := fmt.Sprintf("rounds[%d]", i)
+		if err := binding.Validator.ValidateStruct(&round.CreateRoundRequest); err != nil {
+			fail(path, "%s", err.Error())
+		}
+		if round.RoundNumber != 0 {
+			if setupRounds[round.RoundNumber] {
+				fail(path+".round_number", "round %d appears more than once in the setup", round.RoundNumber)
+			} else if roundNumbers[round.RoundNumber] {
+				fail(path+".round_number", "the tournament already has a round %d", round.RoundNumber)
+			}
+			setupRounds[round.RoundNumber] = true
+		}
+		if round.RoundDate != "" {
+			if _, err := time.Parse("2006-01-02", round.RoundDate); err != nil {
+				fail(path+".round_date", "round_date must be a date like 2006-01-02")
+			}
+		}
+		courseHoles, err := h.checkSetupCourse(round.CreateRoundRequest, path, fail)
+		if err != nil {
+			return nil, nil, err
+		}
+		pointsPerMatch := 1.0
+		if round.PointsPerMatch != nil {
+			if err := validatePointValue("points_per_match", *round.PointsPerMatch); err != nil {
+				fail(path+".points_per_match", "%s", err.Error())
+			}
+			pointsPerMatch = *round.PointsPerMatch
+		}
+
+		roundPlan := setupRoundPlan{req: round.CreateRoundRequest}
+		summary := models.SetupRound{Name: round.Name, RoundNumber: round.RoundNumber, RoundDate: round.RoundDate, Matches: []models.SetupMatch{}}
+		playing := make(map[string]string) // user ID -> path of their match in this round
+		for j, match := range round.Matches {
+			matchPath := fmt.Sprintf("%s.matches[%d]", path, j)
+			if err := binding.Validator.ValidateStruct(&match); err != nil {
+				fail(matchPath, "%s", err.Error())
+			}
+
+			var format *models.MatchFormatDefinition
+			if match.Format != "" {
+				if format, err = lookupFormat(match.Format); err != nil {
+					return nil, nil, err
+				}
+				if format == nil {
+					fail(matchPath+".format", "no match format is named %q", match.Format)
+				}
+			}
+
+			team1, ok1 := setupMatchTeam(match.Team1, 0, len(req.Teams), teamIndex, matchPath+".team1", fail)
+			team2, ok2 := setupMatchTeam(match.Team2, 1, len(req.Teams), teamIndex, matchPath+".team2", fail)
+			if ok1 && ok2 && team1 == team2 {
+				fail(matchPath+".team2", "a match needs two different teams")
+			}
+
+			matchPlan := setupMatchPlan{
+				req: models.CreateMatchRequest{
+					Holes:             match.Holes,
+					HandicapMode:      match.HandicapMode,
+					HandicapAllowance: match.HandicapAllowance,
+					MustProduceWinner: match.MustProduceWinner,
+					StartingHole:      match.StartingHole,
+					HoleSequence:      match.HoleSequence,
+					PointsAvailable:   match.PointsAvailable,
+				},
+				team1:        team1,
+				team2:        team2,
+				team1Players: match.Team1Players,
+				team2Players: match.Team2Players,
+			}
+			if format != nil {
+				matchPlan.req.MatchFormatID = format.ID
+			}
+			if err := validateHoleSequence(&matchPlan.req); err != nil {
+				fail(matchPath+".hole_sequence", "%s", err.Error())
+			}
+			if err := validateCourseHoles(&matchPlan.req, courseHoles); err != nil {
+				fail(matchPath, "%s", err.Error())
+			}
+			points := pointsPerMatch
+			if match.PointsAvailable != nil {
+				if err := validatePointValue("points_available", *match.PointsAvailable); err != nil {
+					fail(matchPath+".points_available", "%s", err.Error())
+				}
+				points = *match.PointsAvailable
+			}
+
+			sides := []struct {
+				field   string
+				team    int
+				ok      bool
+				players []int
+			}{
+				{"team1_players", team1, ok1, match.Team1Players},
+				{"team2_players", team2, ok2, match.Team2Players},
+			}
+			names := make([][]string, len(sides))
+			for s, side := range sides {
+				names[s] = []string{}
+				if !side.ok || side.players == nil {
+					continue
+				}
+				sidePath := matchPath + "." + side.field
+				if format != nil && len(side.players) != format.PlayersPerSide {
+					fail(sidePath, "%s is played %d a side but %d players are listed", format.Name, format.PlayersPerSide, len(side.players))
+				}
+				users := plan.teams[side.team].users
+				for k, index := range side.players {
+					playerPath := fmt.Sprintf("%s[%d]", sidePath, k)
+					if index < 0 || index >= len(users) {
+						fail(playerPath, "%s has no player %d; players are numbered from 0", req.Teams[side.team].Name, index)
+						continue
+					}
+					user := users[index]
+					if user == nil {
+						continue
+					}
+					if other, ok := playing[user.ID]; ok {
+						fail(playerPath, "%s is already playing in %s", user.Name, other)
+						continue
+					}
+					playing[user.ID] = matchPath
+					names[s] = append(names[s], user.Name)
+				}
+			}
+
+			team1Name, team2Name := "", ""
+			if ok1 {
+				team1Name = req.Teams[team1].Name
+			}
+			if ok2 {
+				team2Name = req.Teams[team2].Name
+			}
+			roundPlan.matches = append(roundPlan.matches, matchPlan)
+			summary.Matches = append(summary.Matches, models.SetupMatch{
+				MatchNumber:     j + 1,
+				Format:          match.Format,
+				Holes:           match.Holes,
+				PointsAvailable: points,
+				Team1:           team1Name,
+				Team2:           team2Name,
+				Team1Players:    names[0],
+				Team2Players:    names[1],
+			})
+		}
+
+		plan.rounds = append(plan.rounds, roundPlan)
+		plan.result.Rounds = append(plan.result.Rounds, summary)
+	}
+
+	return plan, setupErrors, nil
+}
+
+// setupMatchTeam resolves a match's team by name, or the setup's team at fallback when no name is given
+func setupMatchTeam(name string, fallback, teams int, teamIndex map[string]int, path string, fail func(path, format string, args ...interface{})) (int, bool) {
+	if name == "" {
+		if fallback >= teams {
+			fail(path, "name the team, as the setup has no team %d to default to", fallback+1)
+			return 0, false
+		}
+		return fallback, true
+	}
+	index, ok := teamIndex[name]
+	if !ok {
+		fail(path, "no team in the setup is named %q", name)
+	}
+	return index, ok
+}
+
+// checkSetupCourse checks a round's course and tee exist, that the tee is on the course and
+// that it has holes. It returns how many holes the tee has, or models.DefaultCourseHoles
+// when the round has no tee.
+func (h *TournamentHandler) checkSetupCourse(round models.CreateRoundRequest, path string, fail func(path, format string, args ...interface{})) (int, error) {
+	if round.CourseID != nil {
+		if _, err := h.repo.GetCourse(*round.CourseID); err != nil {
+			if err.Error() != "course not found" {
+				return 0, err
+			}
+			fail(path+".course_id", "course not found")
+		}
+	}
+	courseHoles := models.DefaultCourseHoles
+	if round.TeeID != nil {
+		tee, err := h.repo.GetTee(*round.TeeID)
+		if err != nil {
+			if err.Error() != "tee not found" {
+				return 0, err
+			}
+			fail(path+".tee_id", "tee not found")
+			return courseHoles, nil
+		}
+		if round.CourseID != nil && tee.CourseID != *round.CourseID {
+			fail(path+".tee_id", "tee %s isn't on course %s", tee.Name, *round.CourseID)
+		}
+		if len(tee.Holes) == 0 {
+			fail(path+".tee_id", "tee %s has no holes; add its scorecard first", tee.Name)
+			return courseHoles, nil
+		}
+		courseHoles = 0
+		for _, hole := range tee.Holes {
+			if hole.HoleNumber > courseHoles {
+				courseHoles = hole.HoleNumber
+			}
+		}
+	}
+	return courseHoles, nil
+}
+
+// createSetup creates a validated plan's teams, rosters, rounds and matches, filling in the
+// plan's result with their IDs. Run it in a transaction so a failure leaves nothing behind.
+func createSetup(tx *repository.Repository, tournamentID string, plan *setupPlan) error {
+	teamIDs := make([]string, len(plan.teams))
+	for i := range plan.teams {
+		team := &plan.teams[i]
+		created, err := tx.CreateTeam(tournamentID, &team.req)
+		if err != nil {
+			return err
+		}
+		teamIDs[i] = created.ID
+		plan.result.Teams[i].ID = created.ID
+		for _, user := range team.users {
+			if _, err := tx.AddTeamMember(created.ID, user.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := range plan.rounds {
+		round := &plan.rounds[i]
+		created, err := tx.CreateRound(tournamentID, &round.req)
+		if err != nil {
+			return err
+		}
+		plan.result.Rounds[i].ID = created.ID
+
+		for j, planned := range round.matches {
+			req := planned.req
+			req.Team1ID, req.Team2ID = teamIDs[planned.team1], teamIDs[planned.team2]
+			req.Team1Players = setupPlayerIDs(plan.teams[planned.team1].users, planned.team1Players)
+			req.Team2Players = setupPlayerIDs(plan.teams[planned.team2].users, planned.team2Players)
+			match, err := tx.CreateMatch(created.ID, &req)
+			if err != nil {
+				return err
+			}
+			plan.result.Rounds[i].Matches[j].ID = match.ID
+			plan.result.Rounds[i].Matches[j].MatchNumber = match.MatchNumber
+			plan.result.Rounds[i].Matches[j].PointsAvailable = match.PointsAvailable
+		}
+	}
+	return nil
+}
+
+// setupPlayerIDs maps player indices onto a team's users; nil leaves the whole team playing
+func setupPlayerIDs(users []*models.User, indices []int) []string {
+	if indices == nil {
+		return nil
+	}
+	ids := make([]string, 0, len(indices))
+	for _, index := range indices {
+		ids = append(ids, users[index].ID)
+	}
+	return ids
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"mayhamapi/models"
+)
+
+func TestSetupMatchTeam(t *testing.T) {
+	teamIndex := map[string]int{"Eagles": 0, "Hawks": 1}
+
+	tests := []struct {
+		name     string
+		team     string
+		fallback int
+		teams    int
+		index    int
+		ok       bool
+		errs     []string
+	}{
+		{"named team", "Hawks", 0, 2, 1, true, nil},
+		{"defaults to the first team", "", 0, 2, 0, true, nil},
+		{"defaults to the second team", "", 1, 2, 1, true, nil},
+		{"unknown team", "Owls", 0, 2, 0, false, []string{`rounds[0].matches[0].team1: no team in the setup is named "Owls"`}},
+		{"no team to default to", "", 1, 1, 0, false, []string{"rounds[0].matches[0].team1: name the team, as the setup has no team 2 to default to"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs []string
+			fail := func(path, format string, args ...interface{}) {
+				errs = append(errs, path+": "+fmt.Sprintf(format, args...))
+			}
+
+			index, ok := setupMatchTeam(tt.team, tt.fallback, tt.teams, teamIndex, "rounds[0].matches[0].team1", fail)
+			if index != tt.index || ok != tt.ok {
+				t.Errorf("setupMatchTeam = %d, %v, want %d, %v", index, ok, tt.index, tt.ok)
+			}
+			if !reflect.DeepEqual(errs, tt.errs) {
+				t.Errorf("errors = %q, want %q", errs, tt.errs)
+			}
+		})
+	}
+}
+
+func TestSetupPlayerIDs(t *testing.T) {
+	users := []*models.User{{ID: "u1"}, {ID: "u2"}, {ID: "u3"}}
+
+	tests := []struct {
+		name    string
+		indices []int
+		want    []string
+	}{
+		{"whole team plays", nil, nil},
+		{"chosen players in order", []int{2, 0}, []string{"u3", "u1"}},
+		{"no players chosen", []int{}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := setupPlayerIDs(users, tt.indices); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("setupPlayerIDs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -40,6 +40,14 @@ func main() {
 	// Initialize services
 	scoringService := scoring.NewScoringService(repo)
 
+	// Maintenance commands run instead of the server, e.g. `mayhamapi recompute -dry-run`
+	if len(os.Args) > 1 {
+		if err := runCommand(scoringService, os.Args[1], os.Args[2:]); err != nil {
+			log.Fatalf("%s failed: %v", os.Args[1], err)
+		}
+		return
+	}
+
 	// Initialize WebSocket hub
 	wsHub := websocket.NewHub()
 	go wsHub.Run()
@@ -47,11 +55,12 @@ func main() {
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(repo)
 	tournamentHandler := handlers.NewTournamentHandler(repo)
-	scoringHandler := handlers.NewScoringHandler(repo, scoringService)
+	scoringHandler := handlers.NewScoringHandler(repo, scoringService, wsHub)
 	groupHandler := handlers.NewGroupHandler(repo)
+	courseHandler := handlers.NewCourseHandler(repo)
 
 	// Setup router
-	router := setupRouter(authHandler, tournamentHandler, scoringHandler, groupHandler, wsHub)
+	router := setupRouter(authHandler, tournamentHandler, scoringHandler, groupHandler, courseHandler, wsHub)
 
 	// Start server
 	port := os.Getenv("PORT")
@@ -70,6 +79,7 @@ func setupRouter(
 	tournamentHandler *handlers.TournamentHandler,
 	scoringHandler *handlers.ScoringHandler,
 	groupHandler *handlers.GroupHandler,
+	courseHandler *handlers.CourseHandler,
 	wsHub *websocket.Hub,
 ) *gin.Engine {
 	r := gin.Default()
@@ -119,10 +129,20 @@ func setupRouter(
 			public.GET("/tournaments/:tournament_id", tournamentHandler.GetTournament)
 			public.GET("/tournaments/:tournament_id/teams", tournamentHandler.GetTeams)
 			public.GET("/tournaments/:tournament_id/rounds", tournamentHandler.GetRounds)
+			public.GET("/tournaments/:tournament_id/cup", scoringHandler.GetCupStatus)
+			public.GET("/tournaments/:tournament_id/leaderboard", scoringHandler.GetLeaderboard)
+			public.GET("/tournaments/:tournament_id/live", scoringHandler.GetLiveFeed)
 			public.GET("/rounds/:round_id/matches", tournamentHandler.GetMatches)
+			public.GET("/rounds/:round_id/rankings", scoringHandler.GetRoundRankings)
+			public.GET("/rounds/:round_id/skins", scoringHandler.GetRoundSkins)
 			public.GET("/matches/:match_id", tournamentHandler.GetMatch)
 			public.GET("/matches/:match_id/scores", scoringHandler.GetMatchScores)
+			public.GET("/matches/:match_id/audit", scoringHandler.GetMatchAudit)
+			public.GET("/matches/:match_id/scorecard", scoringHandler.GetScorecard)
 			public.GET("/match-formats", tournamentHandler.GetMatchFormats)
+			public.GET("/courses", courseHandler.ListCourses)
+			public.GET("/courses/:course_id", courseHandler.GetCourse)
+			public.GET("/tees/:tee_id", courseHandler.GetTee)
 		}
 
 		// Protected routes (authentication required)
@@ -131,6 +151,7 @@ func setupRouter(
 		{
 			// User management
 			protected.GET("/users", authHandler.GetUsers)
+			protected.GET("/users/:user_id/stats", scoringHandler.GetUserStats)
 
 			// Group management
 			protected.POST("/groups", groupHandler.CreateGroup)
@@ -142,13 +163,39 @@ func setupRouter(
 			// Tournament management (admin or tournament creator)
 			protected.POST("/tournaments", tournamentHandler.CreateTournament)
 			protected.POST("/tournaments/:tournament_id/teams", tournamentHandler.CreateTeam)
+			protected.PUT("/tournaments/:tournament_id/cup", tournamentHandler.SetTournamentCup)
 			protected.POST("/teams/:team_id/members", tournamentHandler.AddTeamMember)
 			protected.POST("/tournaments/:tournament_id/rounds", tournamentHandler.CreateRound)
+			protected.POST("/tournaments/:tournament_id/setup", middleware.AdminOnly(), tournamentHandler.SetupTournament)
+			protected.PUT("/rounds/:round_id/course", tournamentHandler.SetRoundCourse)
+			protected.PUT("/rounds/:round_id/skins", scoringHandler.SetRoundSkins)
 			protected.POST("/rounds/:round_id/matches", tournamentHandler.CreateMatch)
+			protected.POST("/match-formats", tournamentHandler.CreateMatchFormat)
+
+			// Course catalog
+			protected.POST("/courses", courseHandler.CreateCourse)
+			protected.POST("/courses/import", courseHandler.ImportCourse)
+			protected.PUT("/courses/:course_id", courseHandler.UpdateCourse)
+			protected.DELETE("/courses/:course_id", courseHandler.DeleteCourse)
+			protected.POST("/courses/:course_id/tees", courseHandler.CreateTee)
+			protected.PUT("/tees/:tee_id", courseHandler.UpdateTee)
+			protected.DELETE("/tees/:tee_id", courseHandler.DeleteTee)
+			protected.PUT("/tees/:tee_id/holes", courseHandler.SetTeeHoles)
 
 			// Scoring (players can submit their own scores)
 			protected.POST("/matches/:match_id/scores", scoringHandler.SubmitScores)
 			protected.PATCH("/matches/:match_id/scores/:hole_number", scoringHandler.UpdateHoleScore)
+			protected.POST("/matches/:match_id/presses", scoringHandler.CreatePress)
+			protected.POST("/matches/:match_id/concessions", scoringHandler.CreateConcession)
+			protected.PUT("/matches/:match_id/points", scoringHandler.SetMatchPoints)
+		}
+
+		// Admin routes
+		admin := api.Group("/admin")
+		admin.Use(middleware.JWTAuth(), middleware.AdminOnly())
+		{
+			admin.POST("/recompute", scoringHandler.RecomputeResults)
+			admin.POST("/rebuild-stats", scoringHandler.RebuildPlayerStats)
 		}
 
 		// WebSocket endpoint (optional auth for real-time updates)
@@ -2,6 +2,7 @@ package models
 
 import (
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 	"time"
 )
@@ -47,8 +48,12 @@ type Tournament struct {
 	GroupID     string    `json:"group_id" db:"group_id"`
 	CreatedBy   string    `json:"created_by" db:"created_by"`
 	Status      string    `json:"status" db:"status"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	// TargetPoints is the score that wins the cup; nil means more than half the points on offer.
+	// DefendingTeamID is the cup holder, which retains the cup on a tie.
+	TargetPoints    *float64  `json:"target_points,omitempty" db:"target_points"`
+	DefendingTeamID *string   `json:"defending_team_id,omitempty" db:"defending_team_id"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
 }
 
 type Team struct {
@@ -75,8 +80,52 @@ type Round struct {
 	RoundDate    time.Time  `json:"round_date" db:"round_date"`
 	StartTime    *time.Time `json:"start_time,omitempty" db:"start_time"`
 	Status       string     `json:"status" db:"status"`
-	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+	CourseID     *string    `json:"course_id,omitempty" db:"course_id"`
+	TeeID        *string    `json:"tee_id,omitempty" db:"tee_id"`
+	// PointsPerMatch is the points_available given to matches created without their own value
+	PointsPerMatch float64   `json:"points_per_match" db:"points_per_match"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type Course struct {
+	ID        string    `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Location  *string   `json:"location,omitempty" db:"location"`
+	Tees      []Tee     `json:"tees,omitempty"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Tee is a set of tee markers on a course, with its own rating, slope and scorecard
+type Tee struct {
+	ID           string    `json:"id" db:"id"`
+	CourseID     string    `json:"course_id" db:"course_id"`
+	Name         string    `json:"name" db:"name"`
+	Color        *string   `json:"color,omitempty" db:"color"`
+	CourseRating *float64  `json:"course_rating,omitempty" db:"course_rating"`
+	Slope        *int      `json:"slope,omitempty" db:"slope"`
+	Holes        []Hole    `json:"holes,omitempty"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Par returns the total par of the tee's holes
+func (t *Tee) Par() int {
+	par := 0
+	for _, hole := range t.Holes {
+		par += hole.Par
+	}
+	return par
+}
+
+type Hole struct {
+	ID          string `json:"id" db:"id"`
+	TeeID       string `json:"tee_id" db:"tee_id"`
+	HoleNumber  int    `json:"hole_number" db:"hole_number"`
+	Par         int    `json:"par" db:"par"`
+	StrokeIndex int    `json:"stroke_index" db:"stroke_index"`
+	Yardage     *int   `json:"yardage,omitempty" db:"yardage"`
 }
 
 // MatchFormat represents the type of golf match format
@@ -89,6 +138,11 @@ const (
 	AlternateShot MatchFormat = "alternate_shot"
 	HighLow       MatchFormat = "high_low"
 	Shamble       MatchFormat = "shamble"
+	StrokePlay    MatchFormat = "stroke_play"
+	Stableford    MatchFormat = "stableford"
+	Nassau        MatchFormat = "nassau"
+	// Custom formats are defined entirely by FormatConfig.Contests
+	Custom MatchFormat = "custom"
 )
 
 // Implement driver.Valuer interface for database storage
@@ -113,40 +167,302 @@ func (mf *MatchFormat) Scan(value interface{}) error {
 	return nil
 }
 
+// FormatConfig holds optional per-format scoring settings, stored as JSON on match_formats.config
+type FormatConfig struct {
+	// StablefordPoints maps a net score relative to par (e.g. -1 for birdie) to points earned.
+	// Scores better than the lowest key earn the lowest key's points; worse than the highest earn 0.
+	StablefordPoints map[int]int `json:"stableford_points,omitempty"`
+	// AutoPress starts a Nassau press automatically whenever a side goes 2 down in the
+	// latest bet on a nine. Without it, presses are called manually.
+	AutoPress bool `json:"auto_press,omitempty"`
+	// HighLowScoring chooses between one point per hole for the combined high+low total
+	// ("combined", the default) and a point each for low ball and high ball ("split")
+	HighLowScoring string `json:"high_low_scoring,omitempty"`
+	// NetDoubleBogey caps each hole at par + 2 + the handicap strokes the player receives there
+	NetDoubleBogey bool `json:"net_double_bogey,omitempty"`
+	// PickUpAt caps each hole at this many strokes
+	PickUpAt int `json:"pick_up_at,omitempty"`
+	// Contests are the point contests played on every hole of a custom format
+	Contests []ContestRule `json:"contests,omitempty"`
+	// Gross scores a custom format's contests without handicap strokes, whatever the match's handicap mode
+	Gross bool `json:"gross,omitempty"`
+}
+
+// ContestRule is one point contest in a custom format: how each side's scores on a hole
+// combine into a team score, and how many points the lower team score wins
+type ContestRule struct {
+	Name    string `json:"name"`
+	Combine string `json:"combine"`          // min, max, sum, nth_best or best_n
+	N       int    `json:"n,omitempty"`      // the place for nth_best (1 = lowest), or how many scores best_n adds up
+	Points  int    `json:"points,omitempty"` // defaults to 1; a tie splits them
+}
+
+// Ways a custom contest combines a side's scores
+const (
+	CombineMin     = "min"
+	CombineMax     = "max"
+	CombineSum     = "sum"
+	CombineNthBest = "nth_best"
+	CombineBestN   = "best_n"
+)
+
+// High-low scoring variants
+const (
+	HighLowCombined = "combined"
+	HighLowSplit    = "split"
+)
+
+// DefaultStablefordPoints is the standard Stableford table
+var DefaultStablefordPoints = map[int]int{-3: 5, -2: 4, -1: 3, 0: 2, 1: 1}
+
+// Implement driver.Valuer interface for database storage
+func (fc FormatConfig) Value() (driver.Value, error) {
+	return json.Marshal(fc)
+}
+
+// Implement sql.Scanner interface for database retrieval
+func (fc *FormatConfig) Scan(value interface{}) error {
+	if value == nil {
+		*fc = FormatConfig{}
+		return nil
+	}
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, fc)
+	case string:
+		return json.Unmarshal([]byte(v), fc)
+	default:
+		return fmt.Errorf("cannot scan %T into FormatConfig", value)
+	}
+}
+
+// MatchFormatDefinition is a row from the match_formats table
+type MatchFormatDefinition struct {
+	ID             string       `json:"id" db:"id"`
+	Name           string       `json:"name" db:"name"`
+	Description    *string      `json:"description,omitempty" db:"description"`
+	PlayersPerSide int          `json:"players_per_side" db:"players_per_side"`
+	ScoringType    MatchFormat  `json:"scoring_type" db:"scoring_type"`
+	Config         FormatConfig `json:"config" db:"config"`
+	CreatedAt      time.Time    `json:"created_at" db:"created_at"`
+}
+
+// Handicap modes for net match play
+const (
+	HandicapGross          = "gross"           // no strokes given
+	HandicapFullDifference = "full_difference" // strokes off the lowest playing handicap in the match
+	HandicapFullHandicap   = "full_handicap"   // every player receives their full playing handicap
+)
+
 type Match struct {
-	ID              string     `json:"id" db:"id"`
-	RoundID         string     `json:"round_id" db:"round_id"`
-	Team1ID         string     `json:"team1_id" db:"team1_id"`
-	Team2ID         string     `json:"team2_id" db:"team2_id"`
-	MatchFormatID   string     `json:"match_format_id" db:"match_format_id"`
-	MatchNumber     int        `json:"match_number" db:"match_number"`
-	Holes           int        `json:"holes" db:"holes"`
-	Status          string     `json:"status" db:"status"`
-	PointsAvailable float64    `json:"points_available" db:"points_available"`
-	Team1Points     float64    `json:"team1_points" db:"team1_points"`
-	Team2Points     float64    `json:"team2_points" db:"team2_points"`
-	StartTime       *time.Time `json:"start_time,omitempty" db:"start_time"`
-	EndTime         *time.Time `json:"end_time,omitempty" db:"end_time"`
-	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+	ID              string  `json:"id" db:"id"`
+	RoundID         string  `json:"round_id" db:"round_id"`
+	Team1ID         string  `json:"team1_id" db:"team1_id"`
+	Team2ID         string  `json:"team2_id" db:"team2_id"`
+	MatchFormatID   string  `json:"match_format_id" db:"match_format_id"`
+	MatchNumber     int     `json:"match_number" db:"match_number"`
+	Holes           int     `json:"holes" db:"holes"`
+	Status          string  `json:"status" db:"status"`
+	PointsAvailable float64 `json:"points_available" db:"points_available"`
+	Team1Points     float64 `json:"team1_points" db:"team1_points"`
+	Team2Points     float64 `json:"team2_points" db:"team2_points"`
+	// HandicapAllowance is a percentage; nil uses the format's default allowance
+	HandicapMode      string   `json:"handicap_mode" db:"handicap_mode"`
+	HandicapAllowance *float64 `json:"handicap_allowance,omitempty" db:"handicap_allowance"`
+	ResultText        *string  `json:"result_text,omitempty" db:"result_text"` // e.g. "3&2", "1 UP", "A/S"
+	// MustProduceWinner sends a tied match to sudden-death playoff holes, numbered after its last hole
+	MustProduceWinner bool `json:"must_produce_winner" db:"must_produce_winner"`
+	// StartingHole and HoleSequence give the course holes the match is played over, in order
+	StartingHole int   `json:"starting_hole" db:"starting_hole"`
+	HoleSequence []int `json:"hole_sequence,omitempty" db:"hole_sequence"`
+	// Probability is the live win/halve/loss estimate, nil until the match is first scored
+	Probability *MatchProbability `json:"probability,omitempty"`
+	// HolesCompleted and Team1Up are the match's progress as of its last saved result. Team1Up
+	// counts ScoringUnit: holes for match play, strokes or points for stroke play and Stableford.
+	HolesCompleted int        `json:"holes_completed" db:"holes_completed"`
+	Team1Up        int        `json:"team1_up" db:"team1_up"`
+	ScoringUnit    string     `json:"scoring_unit" db:"scoring_unit"`
+	StartTime      *time.Time `json:"start_time,omitempty" db:"start_time"`
+	EndTime        *time.Time `json:"end_time,omitempty" db:"end_time"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// DefaultCourseHoles is the course length assumed for rounds played without course data
+const DefaultCourseHoles = 18
+
+// HolesOn returns the match's holes on a course with courseHoles holes. Matches store their
+// full sequence when created; without one the match runs Holes consecutive holes from StartingHole, wrapping from the
+// course's last hole back to 1 for shotgun starts.
+func (m *Match) HolesOn(courseHoles int) []int {
+	if len(m.HoleSequence) > 0 {
+		return m.HoleSequence
+	}
+
+	start := m.StartingHole
+	if start < 1 {
+		start = 1
+	}
+	holes := make([]int, 0, m.Holes)
+	for i := 0; i < m.Holes; i++ {
+		holes = append(holes, (start+i-1)%courseHoles+1)
+	}
+	return holes
+}
+
+// MatchProbability is the chance of each way a match can finish, summing to 1
+type MatchProbability struct {
+	Team1Win float64 `json:"team1_win"`
+	Halve    float64 `json:"halve"`
+	Team2Win float64 `json:"team2_win"`
 }
 
 type MatchPlayer struct {
-	ID       string `json:"id" db:"id"`
-	MatchID  string `json:"match_id" db:"match_id"`
-	UserID   string `json:"user_id" db:"user_id"`
-	TeamID   string `json:"team_id" db:"team_id"`
-	Position int    `json:"position" db:"position"`
+	ID       string  `json:"id" db:"id"`
+	MatchID  string  `json:"match_id" db:"match_id"`
+	UserID   string  `json:"user_id" db:"user_id"`
+	TeamID   string  `json:"team_id" db:"team_id"`
+	Position int     `json:"position" db:"position"`
+	TeeID    *string `json:"tee_id,omitempty" db:"tee_id"` // overrides the round's tee for this player
 }
 
 type Score struct {
-	ID         string    `json:"id" db:"id"`
-	MatchID    string    `json:"match_id" db:"match_id"`
-	UserID     string    `json:"user_id" db:"user_id"`
-	HoleNumber int       `json:"hole_number" db:"hole_number"`
-	Strokes    int       `json:"strokes" db:"strokes"`
+	ID         string `json:"id" db:"id"`
+	MatchID    string `json:"match_id" db:"match_id"`
+	UserID     string `json:"user_id" db:"user_id"`
+	HoleNumber int    `json:"hole_number" db:"hole_number"`
+	Strokes    int    `json:"strokes" db:"strokes"`
+	// AdjustedStrokes is the score after the format's caps (net double bogey, pick up at X)
+	AdjustedStrokes *int      `json:"adjusted_strokes,omitempty" db:"adjusted_strokes"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Counted returns the strokes that count for scoring: the adjusted score when one was stored
+func (s Score) Counted() int {
+	if s.AdjustedStrokes != nil {
+		return *s.AdjustedStrokes
+	}
+	return s.Strokes
+}
+
+// HoleContest is one of several point contests decided on a single hole, such as
+// low ball and high ball in split high-low
+type HoleContest struct {
+	Name         string  `json:"name"` // e.g. "low", "high"
+	Team1Score   int     `json:"team1_score"`
+	Team2Score   int     `json:"team2_score"`
+	WinnerTeamID *string `json:"winner_team_id"` // nil for tie
+	Team1Points  float64 `json:"team1_points"`
+	Team2Points  float64 `json:"team2_points"`
+}
+
+// HoleContests is stored as JSON on hole_results.contests
+type HoleContests []HoleContest
+
+// Implement driver.Valuer interface for database storage
+func (hc HoleContests) Value() (driver.Value, error) {
+	if hc == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]HoleContest(hc))
+}
+
+// Implement sql.Scanner interface for database retrieval
+func (hc *HoleContests) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*hc = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, (*[]HoleContest)(hc))
+	case string:
+		return json.Unmarshal([]byte(v), (*[]HoleContest)(hc))
+	default:
+		return fmt.Errorf("cannot scan %T into HoleContests", value)
+	}
+}
+
+// HoleResult is the stored outcome of one hole after the match format has been applied
+type HoleResult struct {
+	ID           string       `json:"id" db:"id"`
+	MatchID      string       `json:"match_id" db:"match_id"`
+	HoleNumber   int          `json:"hole_number" db:"hole_number"`
+	Team1Score   *int         `json:"team1_score" db:"team1_score"`
+	Team2Score   *int         `json:"team2_score" db:"team2_score"`
+	WinnerTeamID *string      `json:"winner_team_id" db:"winner_team_id"`
+	Team1Points  float64      `json:"team1_points" db:"team1_points"`
+	Team2Points  float64      `json:"team2_points" db:"team2_points"`
+	Contests     HoleContests `json:"contests,omitempty" db:"contests"` // set when a hole has more than one point contest
+	CreatedAt    time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+// Press is a manually called Nassau press: a new bet from StartHole to the end of that nine
+type Press struct {
+	ID        string    `json:"id" db:"id"`
+	MatchID   string    `json:"match_id" db:"match_id"`
+	TeamID    string    `json:"team_id" db:"team_id"` // the side that called the press
+	StartHole int       `json:"start_hole" db:"start_hole"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Concession types
+const (
+	ConcedeHole  = "hole"
+	ConcedePutt  = "putt"
+	ConcedeMatch = "match"
+)
+
+// Concession records a conceded hole, putt or match. Concessions are authoritative
+// over submitted strokes when the match is scored.
+type Concession struct {
+	ID              string  `json:"id" db:"id"`
+	MatchID         string  `json:"match_id" db:"match_id"`
+	Type            string  `json:"type" db:"concession_type"`
+	HoleNumber      *int    `json:"hole_number,omitempty" db:"hole_number"` // nil for match concessions
+	ConcedingTeamID string  `json:"conceding_team_id" db:"conceding_team_id"`
+	UserID          *string `json:"user_id,omitempty" db:"user_id"` // putt concessions: the player whose putt was conceded
+	// Strokes is the player's score for the hole counting the conceded putt (putt concessions only)
+	Strokes    *int      `json:"strokes,omitempty" db:"strokes"`
+	RecordedBy *string   `json:"recorded_by,omitempty" db:"recorded_by"`
 	CreatedAt  time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Match event types recorded in the audit trail
+const (
+	EventScoreSubmitted = "score_submitted"
+	EventScoreUpdated   = "score_updated"
+	EventConcession     = "concession"
+)
+
+// MatchEvent is an audit trail entry for a change to a match's scoring
+type MatchEvent struct {
+	ID         string          `json:"id" db:"id"`
+	MatchID    string          `json:"match_id" db:"match_id"`
+	EventType  string          `json:"event_type" db:"event_type"`
+	HoleNumber *int            `json:"hole_number,omitempty" db:"hole_number"`
+	UserID     *string         `json:"user_id,omitempty" db:"user_id"`         // player the event concerns
+	RecordedBy *string         `json:"recorded_by,omitempty" db:"recorded_by"` // authenticated user who made the change
+	Details    json.RawMessage `json:"details" db:"details"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+}
+
+// Skins scoring modes
+const (
+	SkinsGross = "gross"
+	SkinsNet   = "net"
+)
+
+// SkinsGame is the skins side game run alongside a round's matches
+type SkinsGame struct {
+	RoundID          string    `json:"round_id" db:"round_id"`
+	Mode             string    `json:"mode" db:"mode"`                           // gross or net
+	Carryovers       bool      `json:"carryovers" db:"carryovers"`               // tied holes carry their skin to the next hole
+	ValidationBirdie bool      `json:"validation_birdie" db:"validation_birdie"` // a skin only counts if won with birdie or better
+	Pot              float64   `json:"pot" db:"pot"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
 }
 
 // ============================================
@@ -181,13 +497,110 @@ type CreateRoundRequest struct {
 	RoundNumber int        `json:"round_number" binding:"required"`
 	RoundDate   string     `json:"round_date" binding:"required"`
 	StartTime   *time.Time `json:"start_time,omitempty"`
+	CourseID    *string    `json:"course_id,omitempty"`
+	TeeID       *string    `json:"tee_id,omitempty"`
+	// PointsPerMatch defaults to 1
+	PointsPerMatch *float64 `json:"points_per_match,omitempty"`
+}
+
+type SetRoundCourseRequest struct {
+	CourseID string `json:"course_id" binding:"required"`
+	TeeID    string `json:"tee_id" binding:"required"`
+}
+
+type CreateMatchFormatRequest struct {
+	Name           string       `json:"name" binding:"required"`
+	Description    *string      `json:"description,omitempty"`
+	PlayersPerSide int          `json:"players_per_side" binding:"required,min=1"`
+	ScoringType    MatchFormat  `json:"scoring_type" binding:"required"`
+	Config         FormatConfig `json:"config"`
+}
+
+type SetCupRequest struct {
+	TargetPoints    *float64 `json:"target_points,omitempty"`
+	DefendingTeamID *string  `json:"defending_team_id,omitempty"`
+}
+
+type CreatePressRequest struct {
+	TeamID    string `json:"team_id" binding:"required"`
+	StartHole int    `json:"start_hole" binding:"required,min=1"`
+}
+
+// CreateConcessionRequest records a concession. Hole and match concessions name the
+// conceding team; putt concessions name the player and their score for the hole.
+type CreateConcessionRequest struct {
+	Type       string  `json:"type" binding:"required,oneof=hole putt match"`
+	TeamID     string  `json:"team_id,omitempty"`
+	HoleNumber *int    `json:"hole_number,omitempty" binding:"omitempty,min=1"`
+	UserID     *string `json:"user_id,omitempty"`
+	Strokes    *int    `json:"strokes,omitempty" binding:"omitempty,min=1"`
+}
+
+type SetSkinsGameRequest struct {
+	Mode             string  `json:"mode" binding:"required,oneof=gross net"`
+	Carryovers       *bool   `json:"carryovers,omitempty"` // defaults to true
+	ValidationBirdie bool    `json:"validation_birdie"`
+	Pot              float64 `json:"pot" binding:"min=0"`
+}
+
+type CreateCourseRequest struct {
+	Name     string  `json:"name" binding:"required"`
+	Location *string `json:"location,omitempty"`
+}
+
+type CreateTeeRequest struct {
+	Name         string   `json:"name" binding:"required"`
+	Color        *string  `json:"color,omitempty"`
+	CourseRating *float64 `json:"course_rating,omitempty"`
+	Slope        *int     `json:"slope,omitempty" binding:"omitempty,min=55,max=155"`
+}
+
+type HoleRequest struct {
+	HoleNumber  int  `json:"hole_number" binding:"required,min=1,max=18"`
+	Par         int  `json:"par" binding:"required,min=3,max=6"`
+	StrokeIndex int  `json:"stroke_index" binding:"required,min=1,max=18"`
+	Yardage     *int `json:"yardage,omitempty"`
+}
+
+type SetTeeHolesRequest struct {
+	Holes []HoleRequest `json:"holes" binding:"required,dive"`
+}
+
+// ImportCourseRequest is the JSON scorecard import format: a course with every tee and its holes
+type ImportCourseRequest struct {
+	Name     string             `json:"name" binding:"required"`
+	Location *string            `json:"location,omitempty"`
+	Tees     []ImportTeeRequest `json:"tees" binding:"required,min=1,dive"`
+}
+
+type ImportTeeRequest struct {
+	CreateTeeRequest
+	Holes []HoleRequest `json:"holes" binding:"required,min=1,dive"`
 }
 
 type CreateMatchRequest struct {
-	Team1ID       string `json:"team1_id" binding:"required"`
-	Team2ID       string `json:"team2_id" binding:"required"`
-	MatchFormatID string `json:"match_format_id" binding:"required"`
-	Holes         int    `json:"holes" binding:"required,min=6,max=18"`
+	Team1ID       string   `json:"team1_id" binding:"required"`
+	Team2ID       string   `json:"team2_id" binding:"required"`
+	MatchFormatID string   `json:"match_format_id" binding:"required"`
+	Holes         int      `json:"holes" binding:"required,min=6,max=18"`
+	Team1Players  []string `json:"team1_players,omitempty"`
+	Team2Players  []string `json:"team2_players,omitempty"`
+	// PlayerTees optionally maps a player's user ID to a tee other than the round's
+	PlayerTees map[string]string `json:"player_tees,omitempty"`
+	// HandicapMode is gross, full_difference or full_handicap; defaults to gross
+	HandicapMode      string   `json:"handicap_mode,omitempty" binding:"omitempty,oneof=gross full_difference full_handicap"`
+	HandicapAllowance *float64 `json:"handicap_allowance,omitempty" binding:"omitempty,min=0,max=100"`
+	// MustProduceWinner is for knockout and deciding matches that can't be halved
+	MustProduceWinner bool `json:"must_produce_winner,omitempty"`
+	// StartingHole defaults to 1; HoleSequence optionally lists every hole in playing order
+	StartingHole int   `json:"starting_hole,omitempty" binding:"omitempty,min=1,max=18"`
+	HoleSequence []int `json:"hole_sequence,omitempty" binding:"omitempty,dive,min=1,max=18"`
+	// PointsAvailable defaults to the round's points_per_match
+	PointsAvailable *float64 `json:"points_available,omitempty"`
+}
+
+type SetMatchPointsRequest struct {
+	PointsAvailable float64 `json:"points_available" binding:"required"`
 }
 
 type AddTeamMemberRequest struct {
@@ -204,17 +617,149 @@ type HoleScore struct {
 	Strokes    int    `json:"strokes" binding:"required,min=1"`
 }
 
+// TournamentSetupRequest creates a tournament's teams, rosters, rounds and matches in one go
+type TournamentSetupRequest struct {
+	Teams  []SetupTeamRequest  `json:"teams"`
+	Rounds []SetupRoundRequest `json:"rounds"`
+}
+
+type SetupTeamRequest struct {
+	CreateTeamRequest
+	// Players are the members' email addresses
+	Players []string `json:"players"`
+}
+
+type SetupRoundRequest struct {
+	CreateRoundRequest
+	Matches []SetupMatchRequest `json:"matches"`
+}
+
+// SetupMatchRequest is CreateMatchRequest addressed by name rather than ID: the format by its
+// name, the teams by their name in the request (defaulting to the first and second team), and
+// players by their index in that team's players list
+type SetupMatchRequest struct {
+	Format            string   `json:"format" binding:"required"`
+	Team1             string   `json:"team1,omitempty"`
+	Team2             string   `json:"team2,omitempty"`
+	Team1Players      []int    `json:"team1_players,omitempty"`
+	Team2Players      []int    `json:"team2_players,omitempty"`
+	Holes             int      `json:"holes" binding:"required,min=6,max=18"`
+	HandicapMode      string   `json:"handicap_mode,omitempty" binding:"omitempty,oneof=gross full_difference full_handicap"`
+	HandicapAllowance *float64 `json:"handicap_allowance,omitempty" binding:"omitempty,min=0,max=100"`
+	MustProduceWinner bool     `json:"must_produce_winner,omitempty"`
+	StartingHole      int      `json:"starting_hole,omitempty" binding:"omitempty,min=1,max=18"`
+	HoleSequence      []int    `json:"hole_sequence,omitempty" binding:"omitempty,dive,min=1,max=18"`
+	PointsAvailable   *float64 `json:"points_available,omitempty"`
+}
+
 // ============================================
 // Leaderboard and Statistics Models
 // ============================================
 
-type LeaderboardEntry struct {
-	TeamID      string  `json:"team_id"`
-	TeamName    string  `json:"team_name"`
-	Points      float64 `json:"points"`
+// Leaderboard is a tournament's standings; only the requested view is filled in
+type Leaderboard struct {
+	TournamentID        string               `json:"tournament_id"`
+	View                string               `json:"view"` // team or individual
+	Tiebreakers         []string             `json:"tiebreakers"`
+	TeamStandings       []TeamStanding       `json:"team_standings,omitempty"`
+	IndividualStandings []IndividualStanding `json:"individual_standings,omitempty"`
+}
+
+// TeamStanding is one team's record. Points and match results count completed matches;
+// hole results count every hole decided so far.
+type TeamStanding struct {
+	Rank        int     `json:"rank"`
+	Team        Team    `json:"team"`
+	PointsWon   float64 `json:"points_won"`
+	PointsLost  float64 `json:"points_lost"`
 	MatchesWon  int     `json:"matches_won"`
 	MatchesLost int     `json:"matches_lost"`
 	MatchesTied int     `json:"matches_tied"`
+	HolesWon    int     `json:"holes_won"`
+	HolesLost   int     `json:"holes_lost"`
+	HolesTied   int     `json:"holes_tied"`
+}
+
+// IndividualStanding is one player's record across the matches they've played in,
+// each credited with their side's result
+type IndividualStanding struct {
+	Rank          int           `json:"rank"`
+	User          PlayerSummary `json:"user"`
+	Team          Team          `json:"team"`
+	PointsWon     float64       `json:"points_won"`
+	PointsLost    float64       `json:"points_lost"`
+	MatchesPlayed int           `json:"matches_played"`
+	MatchesWon    int           `json:"matches_won"`
+	MatchesLost   int           `json:"matches_lost"`
+	MatchesTied   int           `json:"matches_tied"`
+	HolesWon      int           `json:"holes_won"`
+	HolesLost     int           `json:"holes_lost"`
+	HolesTied     int           `json:"holes_tied"`
+	// WinPercentage counts a halved match as half a win; 0 before any match is played
+	WinPercentage float64 `json:"win_percentage"`
+}
+
+// LiveFeed lists a tournament's matches in progress, for scoreboards and spectators
+type LiveFeed struct {
+	TournamentID  string      `json:"tournament_id"`
+	ActiveMatches []LiveMatch `json:"active_matches"`
+}
+
+// LiveMatch is a match in progress as of its last saved result
+type LiveMatch struct {
+	ID          string `json:"id"`
+	RoundID     string `json:"round_id"`
+	RoundNumber int    `json:"round_number"`
+	MatchNumber int    `json:"match_number"`
+	Format      string `json:"format"` // scoring type, e.g. match_play
+	FormatName  string `json:"format_name"`
+	// Thru is the number of holes completed; CurrentHole is the course hole being played next,
+	// nil once every hole is done (e.g. heading to a playoff)
+	Thru        int               `json:"thru"`
+	CurrentHole *int              `json:"current_hole"`
+	TotalHoles  int               `json:"total_holes"`
+	Team1       LiveTeam          `json:"team1"`
+	Team2       LiveTeam          `json:"team2"`
+	Team1Up     int               `json:"team1_up"` // positive if team 1 is up, negative if team 2 is, 0 for all square
+	ScoringUnit string            `json:"scoring_unit"`
+	ResultText  *string           `json:"result_text,omitempty"`
+	Probability *MatchProbability `json:"probability,omitempty"`
+	Status      string            `json:"status"`
+	LastUpdate  time.Time         `json:"last_update"`
+}
+
+type LiveTeam struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Color   *string  `json:"color,omitempty"`
+	Players []string `json:"players"` // player names, in playing order
+}
+
+// PlayerStats is a player's running record in one tournament, kept up to date as match
+// results change. Match records and points count completed matches; holes count every
+// hole decided so far.
+type PlayerStats struct {
+	ID            string    `json:"id" db:"id"`
+	TournamentID  string    `json:"tournament_id" db:"tournament_id"`
+	UserID        string    `json:"user_id" db:"user_id"`
+	TeamID        string    `json:"team_id" db:"team_id"`
+	MatchesPlayed int       `json:"matches_played" db:"matches_played"`
+	MatchesWon    int       `json:"matches_won" db:"matches_won"`
+	MatchesLost   int       `json:"matches_lost" db:"matches_lost"`
+	MatchesTied   int       `json:"matches_tied" db:"matches_tied"`
+	PointsWon     float64   `json:"points_won" db:"points_won"`
+	PointsLost    float64   `json:"points_lost" db:"points_lost"`
+	HolesWon      int       `json:"holes_won" db:"holes_won"`
+	HolesLost     int       `json:"holes_lost" db:"holes_lost"`
+	HolesTied     int       `json:"holes_tied" db:"holes_tied"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// PlayerSummary is the public part of a user, without their email
+type PlayerSummary struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Handicap *float64 `json:"handicap,omitempty"`
 }
 
 type MatchResult struct {
@@ -224,7 +769,58 @@ type MatchResult struct {
 	Team1Points    float64 `json:"team1_points"`
 	Team2Points    float64 `json:"team2_points"`
 	WinnerTeamID   *string `json:"winner_team_id"`
+	ResultText     string  `json:"result_text"`
 	Status         string  `json:"status"`
 	Format         string  `json:"format"`
 	HolesCompleted int     `json:"holes_completed"`
 }
+
+// ============================================
+// Tournament Setup Models
+// ============================================
+
+// SetupError points at the element of a setup request that can't be created
+type SetupError struct {
+	Path    string `json:"path"` // e.g. rounds[0].matches[2].team1_players[1]
+	Message string `json:"message"`
+}
+
+// TournamentSetupResult lists what a setup created, or would create on a dry run
+type TournamentSetupResult struct {
+	Tournament *Tournament   `json:"tournament"`
+	DryRun     bool          `json:"dry_run"`
+	Created    SetupCreation `json:"created"`
+}
+
+type SetupCreation struct {
+	Teams  []SetupTeam  `json:"teams"`
+	Rounds []SetupRound `json:"rounds"`
+}
+
+// SetupTeam and the types below leave ID empty on a dry run
+type SetupTeam struct {
+	ID      string          `json:"id,omitempty"`
+	Name    string          `json:"name"`
+	Color   *string         `json:"color,omitempty"`
+	Players []PlayerSummary `json:"players"`
+}
+
+type SetupRound struct {
+	ID          string       `json:"id,omitempty"`
+	Name        string       `json:"name"`
+	RoundNumber int          `json:"round_number"`
+	RoundDate   string       `json:"round_date"`
+	Matches     []SetupMatch `json:"matches"`
+}
+
+type SetupMatch struct {
+	ID              string   `json:"id,omitempty"`
+	MatchNumber     int      `json:"match_number"`
+	Format          string   `json:"format"`
+	Holes           int      `json:"holes"`
+	PointsAvailable float64  `json:"points_available"`
+	Team1           string   `json:"team1"`
+	Team2           string   `json:"team2"`
+	Team1Players    []string `json:"team1_players"`
+	Team2Players    []string `json:"team2_players"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHolesOn(t *testing.T) {
+	tests := []struct {
+		name        string
+		match       Match
+		courseHoles int
+		want        []int
+	}{
+		{"full round", Match{Holes: 18, StartingHole: 1}, 18, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18}},
+		{"no starting hole starts on 1", Match{Holes: 3}, 18, []int{1, 2, 3}},
+		{"back nine", Match{Holes: 9, StartingHole: 10}, 18, []int{10, 11, 12, 13, 14, 15, 16, 17, 18}},
+		{"shotgun start wraps to 1", Match{Holes: 6, StartingHole: 15}, 18, []int{15, 16, 17, 18, 1, 2}},
+		{"shotgun start on a nine-hole course", Match{Holes: 9, StartingHole: 4}, 9, []int{4, 5, 6, 7, 8, 9, 1, 2, 3}},
+		{"eighteen on a nine-hole course goes round twice", Match{Holes: 18, StartingHole: 1}, 9, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 1, 2, 3, 4, 5, 6, 7, 8, 9}},
+		{"stored sequence wins", Match{Holes: 3, StartingHole: 1, HoleSequence: []int{7, 3, 12}}, 18, []int{7, 3, 12}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.match.HolesOn(tt.courseHoles); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("HolesOn(%d) = %v, want %v", tt.courseHoles, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"mayhamapi/models"
+)
+
+// ============================================
+// Course Repository Methods
+// ============================================
+
+func (r *Repository) CreateCourse(req *models.CreateCourseRequest) (*models.Course, error) {
+	query := `
+		INSERT INTO courses (name, location, created_at, updated_at)
+		VALUES ($1, $2, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		RETURNING id, name, location, created_at, updated_at
+	`
+
+	var course models.Course
+	err := r.db.QueryRow(query, req.Name, req.Location).Scan(
+		&course.ID, &course.Name, &course.Location, &course.CreatedAt, &course.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create course: %w", err)
+	}
+
+	return &course, nil
+}
+
+// GetCourse returns a course with all of its tees and their holes
+func (r *Repository) GetCourse(id string) (*models.Course, error) {
+	query := `SELECT id, name, location, created_at, updated_at FROM courses WHERE id = $1`
+
+	var course models.Course
+	err := r.db.QueryRow(query, id).Scan(
+		&course.ID, &course.Name, &course.Location, &course.CreatedAt, &course.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("course not found")
+		}
+		return nil, fmt.Errorf("failed to get course: %w", err)
+	}
+
+	tees, err := r.GetTeesByCourse(id)
+	if err != nil {
+		return nil, err
+	}
+	course.Tees = tees
+
+	return &course, nil
+}
+
+func (r *Repository) ListCourses() ([]models.Course, error) {
+	query := `SELECT id, name, location, created_at, updated_at FROM courses ORDER BY name`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list courses: %w", err)
+	}
+	defer rows.Close()
+
+	var courses []models.Course
+	for rows.Next() {
+		var course models.Course
+		err := rows.Scan(&course.ID, &course.Name, &course.Location, &course.CreatedAt, &course.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan course: %w", err)
+		}
+		courses = append(courses, course)
+	}
+
+	return courses, nil
+}
+
+func (r *Repository) UpdateCourse(id string, req *models.CreateCourseRequest) (*models.Course, error) {
+	query := `
+		UPDATE courses SET name = $1, location = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+		RETURNING id, name, location, created_at, updated_at
+	`
+
+	var course models.Course
+	err := r.db.QueryRow(query, req.Name, req.Location, id).Scan(
+		&course.ID, &course.Name, &course.Location, &course.CreatedAt, &course.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("course not found")
+		}
+		return nil, fmt.Errorf("failed to update course: %w", err)
+	}
+
+	return &course, nil
+}
+
+func (r *Repository) DeleteCourse(id string) error {
+	result, err := r.db.Exec(`DELETE FROM courses WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete course: %w", err)
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("course not found")
+	}
+
+	return nil
+}
+
+// ============================================
+// Tee Repository Methods
+// ============================================
+
+const teeColumns = `id, course_id, name, color, course_rating, slope, created_at, updated_at`
+
+func scanTee(row rowScanner, tee *models.Tee) error {
+	return row.Scan(
+		&tee.ID, &tee.CourseID, &tee.Name, &tee.Color, &tee.CourseRating, &tee.Slope,
+		&tee.CreatedAt, &tee.UpdatedAt,
+	)
+}
+
+func (r *Repository) CreateTee(courseID string, req *models.CreateTeeRequest) (*models.Tee, error) {
+	query := `
+		INSERT INTO tees (course_id, name, color, course_rating, slope, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		RETURNING ` + teeColumns
+
+	var tee models.Tee
+	err := scanTee(r.db.QueryRow(query, courseID, req.Name, req.Color, req.CourseRating, req.Slope), &tee)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tee: %w", err)
+	}
+
+	return &tee, nil
+}
+
+// GetTee returns a tee with its holes
+func (r *Repository) GetTee(id string) (*models.Tee, error) {
+	query := `SELECT ` + teeColumns + ` FROM tees WHERE id = $1`
+
+	var tee models.Tee
+	err := scanTee(r.db.QueryRow(query, id), &tee)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("tee not found")
+		}
+		return nil, fmt.Errorf("failed to get tee: %w", err)
+	}
+
+	holes, err := r.GetTeeHoles(id)
+	if err != nil {
+		return nil, err
+	}
+	tee.Holes = holes
+
+	return &tee, nil
+}
+
+func (r *Repository) GetTeesByCourse(courseID string) ([]models.Tee, error) {
+	query := `SELECT ` + teeColumns + ` FROM tees WHERE course_id = $1 ORDER BY name`
+
+	rows, err := r.db.Query(query, courseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tees: %w", err)
+	}
+	defer rows.Close()
+
+	var tees []models.Tee
+	for rows.Next() {
+		var tee models.Tee
+		if err := scanTee(rows, &tee); err != nil {
+			return nil, fmt.Errorf("failed to scan tee: %w", err)
+		}
+		tees = append(tees, tee)
+	}
+	rows.Close()
+
+	for i := range tees {
+		holes, err := r.GetTeeHoles(tees[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		tees[i].Holes = holes
+	}
+
+	return tees, nil
+}
+
+func (r *Repository) UpdateTee(id string, req *models.CreateTeeRequest) (*models.Tee, error) {
+	query := `
+		UPDATE tees SET name = $1, color = $2, course_rating = $3, slope = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5
+		RETURNING ` + teeColumns
+
+	var tee models.Tee
+	err := scanTee(r.db.QueryRow(query, req.Name, req.Color, req.CourseRating, req.Slope, id), &tee)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("tee not found")
+		}
+		return nil, fmt.Errorf("failed to update tee: %w", err)
+	}
+
+	return &tee, nil
+}
+
+func (r *Repository) DeleteTee(id string) error {
+	result, err := r.db.Exec(`DELETE FROM tees WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete tee: %w", err)
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("tee not found")
+	}
+
+	return nil
+}
+
+// ============================================
+// Hole Repository Methods
+// ============================================
+
+func (r *Repository) GetTeeHoles(teeID string) ([]models.Hole, error) {
+	query := `SELECT id, tee_id, hole_number, par, stroke_index, yardage FROM holes WHERE tee_id = $1 ORDER BY hole_number`
+
+	rows, err := r.db.Query(query, teeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get holes: %w", err)
+	}
+	defer rows.Close()
+
+	var holes []models.Hole
+	for rows.Next() {
+		var hole models.Hole
+		err := rows.Scan(&hole.ID, &hole.TeeID, &hole.HoleNumber, &hole.Par, &hole.StrokeIndex, &hole.Yardage)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan hole: %w", err)
+		}
+		holes = append(holes, hole)
+	}
+
+	return holes, nil
+}
+
+// SetTeeHoles replaces a tee's scorecard with the given holes
+func (r *Repository) SetTeeHoles(teeID string, holes []models.HoleRequest) ([]models.Hole, error) {
+	err := r.WithTx(func(tx *Repository) error {
+		if _, err := tx.db.Exec(`DELETE FROM holes WHERE tee_id = $1`, teeID); err != nil {
+			return fmt.Errorf("failed to clear holes: %w", err)
+		}
+
+		query := `
+			INSERT INTO holes (tee_id, hole_number, par, stroke_index, yardage)
+			VALUES ($1, $2, $3, $4, $5)
+		`
+		for _, hole := range holes {
+			if _, err := tx.db.Exec(query, teeID, hole.HoleNumber, hole.Par, hole.StrokeIndex, hole.Yardage); err != nil {
+				return fmt.Errorf("failed to save hole %d: %w", hole.HoleNumber, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetTeeHoles(teeID)
+}
+
+// ImportCourse creates a course with all of its tees and holes in one transaction
+func (r *Repository) ImportCourse(req *models.ImportCourseRequest) (*models.Course, error) {
+	var courseID string
+	err := r.WithTx(func(tx *Repository) error {
+		course, err := tx.CreateCourse(&models.CreateCourseRequest{Name: req.Name, Location: req.Location})
+		if err != nil {
+			return err
+		}
+		courseID = course.ID
+
+		for _, teeReq := range req.Tees {
+			tee, err := tx.CreateTee(course.ID, &teeReq.CreateTeeRequest)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.SetTeeHoles(tee.ID, teeReq.Holes); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetCourse(courseID)
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"mayhamapi/models"
+
+	"github.com/lib/pq"
+)
+
+// ============================================
+// Live Feed Repository Methods
+// ============================================
+
+// GetLiveMatches returns a tournament's in-progress matches with their teams and players,
+// read from the progress saved with each result in two queries however many matches there are
+func (r *Repository) GetLiveMatches(tournamentID string) ([]models.LiveMatch, error) {
+	query := `
+		SELECT m.id, m.round_id, r.round_number, m.match_number, f.scoring_type, f.name,
+			m.holes, m.starting_hole, m.hole_sequence,
+			(SELECT MAX(h.hole_number) FROM holes h WHERE h.tee_id = r.tee_id), m.holes_completed, m.team1_up, m.scoring_unit,
+			m.result_text, m.team1_win_probability, m.halve_probability, m.team2_win_probability,
+			m.status, m.updated_at,
+			t1.id, t1.name, t1.color, t2.id, t2.name, t2.color
+		FROM matches m
+		JOIN rounds r ON r.id = m.round_id
+		JOIN match_formats f ON f.id = m.match_format_id
+		JOIN teams t1 ON t1.id = m.team1_id
+		JOIN teams t2 ON t2.id = m.team2_id
+		WHERE r.tournament_id = $1 AND m.status = 'in_progress'
+		ORDER BY r.round_number, m.match_number
+	`
+
+	rows, err := r.db.Query(query, tournamentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get live matches: %w", err)
+	}
+	defer rows.Close()
+
+	matches := []models.LiveMatch{}
+	index := make(map[string]int)
+	for rows.Next() {
+		var live models.LiveMatch
+		var played models.Match
+		var sequence pq.Int64Array
+		var courseHoles sql.NullInt64
+		var team1Win, halve, team2Win sql.NullFloat64
+		err := rows.Scan(
+			&live.ID, &live.RoundID, &live.RoundNumber, &live.MatchNumber, &live.Format, &live.FormatName,
+			&played.Holes, &played.StartingHole, &sequence, &courseHoles, &live.Thru, &live.Team1Up, &live.ScoringUnit,
+			&live.ResultText, &team1Win, &halve, &team2Win,
+			&live.Status, &live.LastUpdate,
+			&live.Team1.ID, &live.Team1.Name, &live.Team1.Color, &live.Team2.ID, &live.Team2.Name, &live.Team2.Color,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan live match: %w", err)
+		}
+
+		for _, hole := range sequence {
+			played.HoleSequence = append(played.HoleSequence, int(hole))
+		}
+		setLiveProgress(&live, &played, courseHoles)
+		if team1Win.Valid && halve.Valid && team2Win.Valid {
+			live.Probability = &models.MatchProbability{Team1Win: team1Win.Float64, Halve: halve.Float64, Team2Win: team2Win.Float64}
+		}
+		live.Team1.Players, live.Team2.Players = []string{}, []string{}
+
+		index[live.ID] = len(matches)
+		matches = append(matches, live)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating live matches: %w", err)
+	}
+
+	if len(matches) == 0 {
+		return matches, nil
+	}
+
+	// Players come from match_players, or the whole team when none were assigned
+	matchIDs := make([]string, 0, len(matches))
+	for _, live := range matches {
+		matchIDs = append(matchIDs, live.ID)
+	}
+	playersQuery := `
+		SELECT mp.match_id, mp.team_id, u.name, COALESCE(mp.player_order, 0)
+		FROM match_players mp
+		JOIN users u ON u.id = mp.user_id
+		WHERE mp.match_id = ANY($1)
+		UNION ALL
+		SELECT m.id, tm.team_id, u.name, 0
+		FROM matches m
+		JOIN team_members tm ON tm.team_id IN (m.team1_id, m.team2_id)
+		JOIN users u ON u.id = tm.user_id
+		WHERE m.id = ANY($1) AND NOT EXISTS (SELECT 1 FROM match_players mp WHERE mp.match_id = m.id)
+		ORDER BY 1, 4, 3
+	`
+
+	playerRows, err := r.db.Query(playersQuery, pq.Array(matchIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get live match players: %w", err)
+	}
+	defer playerRows.Close()
+
+	for playerRows.Next() {
+		var matchID, teamID, name string
+		var position int
+		if err := playerRows.Scan(&matchID, &teamID, &name, &position); err != nil {
+			return nil, fmt.Errorf("failed to scan live match player: %w", err)
+		}
+		live := &matches[index[matchID]]
+		switch teamID {
+		case live.Team1.ID:
+			live.Team1.Players = append(live.Team1.Players, name)
+		case live.Team2.ID:
+			live.Team2.Players = append(live.Team2.Players, name)
+		}
+	}
+	if err = playerRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating live match players: %w", err)
+	}
+
+	return matches, nil
+}
+
+// setLiveProgress fills in a live match's length and the course hole it's playing next, from
+// the holes the match is played over on a course of courseHoles holes (18 when unknown)
+func setLiveProgress(live *models.LiveMatch, played *models.Match, courseHoles sql.NullInt64) {
+	holes := played.HolesOn(models.DefaultCourseHoles)
+	if courseHoles.Valid {
+		holes = played.HolesOn(int(courseHoles.Int64))
+	}
+	live.TotalHoles = len(holes)
+	live.CurrentHole = nil
+	if live.Thru < len(holes) {
+		live.CurrentHole = &holes[live.Thru]
+	}
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+
+	"mayhamapi/models"
+)
+
+func TestSetLiveProgress(t *testing.T) {
+	unknown := sql.NullInt64{}
+	nine := sql.NullInt64{Int64: 9, Valid: true}
+
+	tests := []struct {
+		name        string
+		played      models.Match
+		courseHoles sql.NullInt64
+		thru        int
+		total       int
+		current     *int // nil once every hole is done
+	}{
+		{"not started", models.Match{Holes: 18, StartingHole: 1}, unknown, 0, 18, intPtr(1)},
+		{"thru seven", models.Match{Holes: 18, StartingHole: 1}, unknown, 7, 18, intPtr(8)},
+		{"every hole done", models.Match{Holes: 18, StartingHole: 1}, unknown, 18, 18, nil},
+		{"back nine", models.Match{Holes: 9, StartingHole: 10}, unknown, 3, 9, intPtr(13)},
+		{"shotgun start wrapping past 18", models.Match{Holes: 6, StartingHole: 16}, unknown, 3, 6, intPtr(1)},
+		{"shotgun start on a nine-hole course", models.Match{Holes: 9, StartingHole: 7}, nine, 3, 9, intPtr(1)},
+		{"stored sequence", models.Match{Holes: 3, HoleSequence: []int{5, 2, 8}}, nine, 2, 3, intPtr(8)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			live := models.LiveMatch{Thru: tt.thru}
+			setLiveProgress(&live, &tt.played, tt.courseHoles)
+
+			if live.TotalHoles != tt.total {
+				t.Errorf("total holes = %d, want %d", live.TotalHoles, tt.total)
+			}
+			switch {
+			case tt.current == nil && live.CurrentHole != nil:
+				t.Errorf("current hole = %d, want none", *live.CurrentHole)
+			case tt.current != nil && (live.CurrentHole == nil || *live.CurrentHole != *tt.current):
+				t.Errorf("current hole = %v, want %d", live.CurrentHole, *tt.current)
+			}
+		})
+	}
+}
+
+func intPtr(i int) *int { return &i }
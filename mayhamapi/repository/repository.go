@@ -2,35 +2,76 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"mayhamapi/db"
 	"mayhamapi/models"
+
+	"github.com/lib/pq"
 )
 
+// dbtx is the subset of *sql.DB and *sql.Tx used by repository methods
+type dbtx interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
 type Repository struct {
-	db *db.DB
+	db   dbtx
+	conn *db.DB // nil when the repository is bound to a transaction
 }
 
 func NewRepository(database *db.DB) *Repository {
-	return &Repository{db: database}
+	return &Repository{db: database, conn: database}
+}
+
+// WithTx runs fn with a repository bound to a single transaction, committing if fn
+// returns nil and rolling back otherwise. Nested calls reuse the outer transaction.
+func (r *Repository) WithTx(fn func(tx *Repository) error) error {
+	if r.conn == nil {
+		return fn(r)
+	}
+
+	tx, err := r.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(&Repository{db: tx}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
 }
 
 // ============================================
 // Tournament Repository Methods
 // ============================================
 
+const tournamentColumns = `id, name, description, start_date, end_date, group_id, created_by, status, target_points, defending_team_id, created_at, updated_at`
+
+func scanTournament(row rowScanner, tournament *models.Tournament) error {
+	return row.Scan(
+		&tournament.ID, &tournament.Name, &tournament.Description, &tournament.StartDate,
+		&tournament.EndDate, &tournament.GroupID, &tournament.CreatedBy, &tournament.Status,
+		&tournament.TargetPoints, &tournament.DefendingTeamID, &tournament.CreatedAt, &tournament.UpdatedAt,
+	)
+}
+
 func (r *Repository) CreateTournament(req *models.CreateTournamentRequest, createdBy string) (*models.Tournament, error) {
 	query := `
 		INSERT INTO tournaments (name, description, start_date, end_date, group_id, created_by, status, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, 'draft', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-		RETURNING id, name, description, start_date, end_date, group_id, created_by, status, created_at, updated_at
-	`
+		RETURNING ` + tournamentColumns
 
 	var tournament models.Tournament
-	err := r.db.QueryRow(query, req.Name, req.Description, req.StartDate, req.EndDate, req.GroupID, createdBy).Scan(
-		&tournament.ID, &tournament.Name, &tournament.Description, &tournament.StartDate,
-		&tournament.EndDate, &tournament.GroupID, &tournament.CreatedBy, &tournament.Status, &tournament.CreatedAt, &tournament.UpdatedAt,
-	)
+	err := scanTournament(r.db.QueryRow(query, req.Name, req.Description, req.StartDate, req.EndDate, req.GroupID, createdBy), &tournament)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create tournament: %w", err)
@@ -40,13 +81,10 @@ func (r *Repository) CreateTournament(req *models.CreateTournamentRequest, creat
 }
 
 func (r *Repository) GetTournament(id string) (*models.Tournament, error) {
-	query := `SELECT id, name, description, start_date, end_date, group_id, created_by, status, created_at, updated_at FROM tournaments WHERE id = $1`
+	query := `SELECT ` + tournamentColumns + ` FROM tournaments WHERE id = $1`
 
 	var tournament models.Tournament
-	err := r.db.QueryRow(query, id).Scan(
-		&tournament.ID, &tournament.Name, &tournament.Description, &tournament.StartDate,
-		&tournament.EndDate, &tournament.GroupID, &tournament.CreatedBy, &tournament.Status, &tournament.CreatedAt, &tournament.UpdatedAt,
-	)
+	err := scanTournament(r.db.QueryRow(query, id), &tournament)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -59,7 +97,7 @@ func (r *Repository) GetTournament(id string) (*models.Tournament, error) {
 }
 
 func (r *Repository) ListTournaments() ([]models.Tournament, error) {
-	query := `SELECT id, name, description, start_date, end_date, group_id, created_by, status, created_at, updated_at FROM tournaments ORDER BY created_at DESC`
+	query := `SELECT ` + tournamentColumns + ` FROM tournaments ORDER BY created_at DESC`
 
 	rows, err := r.db.Query(query)
 	if err != nil {
@@ -70,11 +108,7 @@ func (r *Repository) ListTournaments() ([]models.Tournament, error) {
 	var tournaments []models.Tournament
 	for rows.Next() {
 		var tournament models.Tournament
-		err := rows.Scan(
-			&tournament.ID, &tournament.Name, &tournament.Description, &tournament.StartDate,
-			&tournament.EndDate, &tournament.GroupID, &tournament.CreatedBy, &tournament.Status, &tournament.CreatedAt, &tournament.UpdatedAt,
-		)
-		if err != nil {
+		if err := scanTournament(rows, &tournament); err != nil {
 			return nil, fmt.Errorf("failed to scan tournament: %w", err)
 		}
 		tournaments = append(tournaments, tournament)
@@ -83,6 +117,26 @@ func (r *Repository) ListTournaments() ([]models.Tournament, error) {
 	return tournaments, nil
 }
 
+// SetTournamentCup saves the cup's target score and defending team
+func (r *Repository) SetTournamentCup(id string, req *models.SetCupRequest) (*models.Tournament, error) {
+	query := `
+		UPDATE tournaments SET target_points = $1, defending_team_id = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+		RETURNING ` + tournamentColumns
+
+	var tournament models.Tournament
+	err := scanTournament(r.db.QueryRow(query, req.TargetPoints, req.DefendingTeamID, id), &tournament)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("tournament not found")
+		}
+		return nil, fmt.Errorf("failed to set tournament cup: %w", err)
+	}
+
+	return &tournament, nil
+}
+
 // ============================================
 // Team Repository Methods
 // ============================================
@@ -149,22 +203,50 @@ func (r *Repository) AddTeamMember(teamID, userID string) (*models.TeamMember, e
 	return &member, nil
 }
 
+func (r *Repository) GetTeamMembers(teamID string) ([]models.TeamMember, error) {
+	query := `SELECT id, team_id, user_id, created_at FROM team_members WHERE team_id = $1 ORDER BY created_at`
+
+	rows, err := r.db.Query(query, teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get team members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []models.TeamMember
+	for rows.Next() {
+		var member models.TeamMember
+		err := rows.Scan(&member.ID, &member.TeamID, &member.UserID, &member.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan team member: %w", err)
+		}
+		members = append(members, member)
+	}
+
+	return members, nil
+}
+
 // ============================================
 // Round Repository Methods
 // ============================================
 
+const roundColumns = `id, tournament_id, name, round_number, round_date, start_time, status, course_id, tee_id, points_per_match, created_at, updated_at`
+
+func scanRound(row rowScanner, round *models.Round) error {
+	return row.Scan(
+		&round.ID, &round.TournamentID, &round.Name, &round.RoundNumber,
+		&round.RoundDate, &round.StartTime, &round.Status, &round.CourseID, &round.TeeID,
+		&round.PointsPerMatch, &round.CreatedAt, &round.UpdatedAt,
+	)
+}
+
 func (r *Repository) CreateRound(tournamentID string, req *models.CreateRoundRequest) (*models.Round, error) {
 	query := `
-		INSERT INTO rounds (tournament_id, name, round_number, round_date, start_time, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, 'scheduled', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-		RETURNING id, tournament_id, name, round_number, round_date, start_time, status, created_at, updated_at
-	`
+		INSERT INTO rounds (tournament_id, name, round_number, round_date, start_time, course_id, tee_id, points_per_match, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE($8, 1.0), 'scheduled', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		RETURNING ` + roundColumns
 
 	var round models.Round
-	err := r.db.QueryRow(query, tournamentID, req.Name, req.RoundNumber, req.RoundDate, req.StartTime).Scan(
-		&round.ID, &round.TournamentID, &round.Name, &round.RoundNumber,
-		&round.RoundDate, &round.StartTime, &round.Status, &round.CreatedAt, &round.UpdatedAt,
-	)
+	err := scanRound(r.db.QueryRow(query, tournamentID, req.Name, req.RoundNumber, req.RoundDate, req.StartTime, req.CourseID, req.TeeID, req.PointsPerMatch), &round)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create round: %w", err)
@@ -174,7 +256,7 @@ func (r *Repository) CreateRound(tournamentID string, req *models.CreateRoundReq
 }
 
 func (r *Repository) GetRoundsByTournament(tournamentID string) ([]models.Round, error) {
-	query := `SELECT id, tournament_id, name, round_number, round_date, start_time, status, created_at, updated_at FROM rounds WHERE tournament_id = $1 ORDER BY round_number`
+	query := `SELECT ` + roundColumns + ` FROM rounds WHERE tournament_id = $1 ORDER BY round_number`
 
 	rows, err := r.db.Query(query, tournamentID)
 	if err != nil {
@@ -185,11 +267,7 @@ func (r *Repository) GetRoundsByTournament(tournamentID string) ([]models.Round,
 	var rounds []models.Round
 	for rows.Next() {
 		var round models.Round
-		err := rows.Scan(
-			&round.ID, &round.TournamentID, &round.Name, &round.RoundNumber,
-			&round.RoundDate, &round.StartTime, &round.Status, &round.CreatedAt, &round.UpdatedAt,
-		)
-		if err != nil {
+		if err := scanRound(rows, &round); err != nil {
 			return nil, fmt.Errorf("failed to scan round: %w", err)
 		}
 		rounds = append(rounds, round)
@@ -198,10 +276,103 @@ func (r *Repository) GetRoundsByTournament(tournamentID string) ([]models.Round,
 	return rounds, nil
 }
 
+func (r *Repository) GetRound(id string) (*models.Round, error) {
+	query := `SELECT ` + roundColumns + ` FROM rounds WHERE id = $1`
+
+	var round models.Round
+	err := scanRound(r.db.QueryRow(query, id), &round)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("round not found")
+		}
+		return nil, fmt.Errorf("failed to get round: %w", err)
+	}
+
+	return &round, nil
+}
+
+// GetRoundCourseHoles returns the highest hole number on the round's tee, or
+// models.DefaultCourseHoles when the round has no course set
+func (r *Repository) GetRoundCourseHoles(roundID string) (int, error) {
+	var teeID sql.NullString
+	if err := r.db.QueryRow(`SELECT tee_id FROM rounds WHERE id = $1`, roundID).Scan(&teeID); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("round not found")
+		}
+		return 0, fmt.Errorf("failed to get round tee: %w", err)
+	}
+	if !teeID.Valid {
+		return models.DefaultCourseHoles, nil
+	}
+
+	var holes sql.NullInt64
+	if err := r.db.QueryRow(`SELECT MAX(hole_number) FROM holes WHERE tee_id = $1`, teeID.String).Scan(&holes); err != nil {
+		return 0, fmt.Errorf("failed to get round course holes: %w", err)
+	}
+	if !holes.Valid {
+		return 0, fmt.Errorf("round tee has no holes")
+	}
+	return int(holes.Int64), nil
+}
+
+func (r *Repository) SetRoundCourse(roundID, courseID, teeID string) (*models.Round, error) {
+	query := `
+		UPDATE rounds SET course_id = $1, tee_id = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND EXISTS (SELECT 1 FROM tees WHERE id = $2 AND course_id = $1)
+		RETURNING ` + roundColumns
+
+	var round models.Round
+	err := scanRound(r.db.QueryRow(query, courseID, teeID, roundID), &round)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("round or tee not found")
+		}
+		return nil, fmt.Errorf("failed to set round course: %w", err)
+	}
+
+	return &round, nil
+}
+
 // ============================================
 // Match Repository Methods
 // ============================================
 
+const matchColumns = `id, round_id, team1_id, team2_id, match_format_id, match_number, holes, status, points_available, team1_points, team2_points, handicap_mode, handicap_allowance, result_text, must_produce_winner, starting_hole, hole_sequence, team1_win_probability, halve_probability, team2_win_probability, holes_completed, team1_up, scoring_unit, created_at, updated_at`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanMatch(row rowScanner, match *models.Match) error {
+	var sequence pq.Int64Array
+	var team1Win, halve, team2Win sql.NullFloat64
+	err := row.Scan(
+		&match.ID, &match.RoundID, &match.Team1ID, &match.Team2ID, &match.MatchFormatID,
+		&match.MatchNumber, &match.Holes, &match.Status, &match.PointsAvailable,
+		&match.Team1Points, &match.Team2Points, &match.HandicapMode, &match.HandicapAllowance,
+		&match.ResultText, &match.MustProduceWinner, &match.StartingHole, &sequence,
+		&team1Win, &halve, &team2Win, &match.HolesCompleted, &match.Team1Up, &match.ScoringUnit,
+		&match.CreatedAt, &match.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	match.Probability = nil
+	if team1Win.Valid && halve.Valid && team2Win.Valid {
+		match.Probability = &models.MatchProbability{Team1Win: team1Win.Float64, Halve: halve.Float64, Team2Win: team2Win.Float64}
+	}
+
+	match.HoleSequence = nil
+	for _, hole := range sequence {
+		match.HoleSequence = append(match.HoleSequence, int(hole))
+	}
+	return nil
+}
+
 func (r *Repository) CreateMatch(roundID string, req *models.CreateMatchRequest) (*models.Match, error) {
 	// First, get the next match number for this round
 	var nextMatchNumber int
@@ -212,34 +383,54 @@ func (r *Repository) CreateMatch(roundID string, req *models.CreateMatchRequest)
 	}
 
 	query := `
-		INSERT INTO matches (round_id, team1_id, team2_id, match_format_id, match_number, holes, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, 'scheduled', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-		RETURNING id, round_id, team1_id, team2_id, match_format_id, match_number, holes, status, points_available, team1_points, team2_points, created_at, updated_at
-	`
+		INSERT INTO matches (round_id, team1_id, team2_id, match_format_id, match_number, holes, status, handicap_mode, handicap_allowance, must_produce_winner, starting_hole, hole_sequence, points_available, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, 'scheduled', $7, $8, $9, $10, $11,
+			COALESCE($12, (SELECT points_per_match FROM rounds WHERE id = $1)), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		RETURNING ` + matchColumns
+
+	handicapMode := req.HandicapMode
+	if handicapMode == "" {
+		handicapMode = models.HandicapGross
+	}
+
+	// Store the full playing order so scoring doesn't depend on how it was specified
+	courseHoles, err := r.GetRoundCourseHoles(roundID)
+	if err != nil {
+		return nil, err
+	}
+	planned := models.Match{Holes: req.Holes, StartingHole: req.StartingHole, HoleSequence: req.HoleSequence}
+	sequence := make(pq.Int64Array, 0, req.Holes)
+	for _, hole := range planned.HolesOn(courseHoles) {
+		sequence = append(sequence, int64(hole))
+	}
 
 	var match models.Match
-	err = r.db.QueryRow(query, roundID, req.Team1ID, req.Team2ID, req.MatchFormatID, nextMatchNumber, req.Holes).Scan(
-		&match.ID, &match.RoundID, &match.Team1ID, &match.Team2ID, &match.MatchFormatID,
-		&match.MatchNumber, &match.Holes, &match.Status, &match.PointsAvailable,
-		&match.Team1Points, &match.Team2Points, &match.CreatedAt, &match.UpdatedAt,
-	)
+	err = scanMatch(r.db.QueryRow(query, roundID, req.Team1ID, req.Team2ID, req.MatchFormatID, nextMatchNumber, req.Holes,
+		handicapMode, req.HandicapAllowance, req.MustProduceWinner, sequence[0], sequence, req.PointsAvailable), &match)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create match: %w", err)
 	}
 
+	for i, userID := range req.Team1Players {
+		if _, err := r.AddMatchPlayer(match.ID, userID, match.Team1ID, i+1, playerTee(req, userID)); err != nil {
+			return nil, err
+		}
+	}
+	for i, userID := range req.Team2Players {
+		if _, err := r.AddMatchPlayer(match.ID, userID, match.Team2ID, i+1, playerTee(req, userID)); err != nil {
+			return nil, err
+		}
+	}
+
 	return &match, nil
 }
 
 func (r *Repository) GetMatch(id string) (*models.Match, error) {
-	query := `SELECT id, round_id, team1_id, team2_id, match_format_id, match_number, holes, status, points_available, team1_points, team2_points, created_at, updated_at FROM matches WHERE id = $1`
+	query := `SELECT ` + matchColumns + ` FROM matches WHERE id = $1`
 
 	var match models.Match
-	err := r.db.QueryRow(query, id).Scan(
-		&match.ID, &match.RoundID, &match.Team1ID, &match.Team2ID, &match.MatchFormatID,
-		&match.MatchNumber, &match.Holes, &match.Status, &match.PointsAvailable,
-		&match.Team1Points, &match.Team2Points, &match.CreatedAt, &match.UpdatedAt,
-	)
+	err := scanMatch(r.db.QueryRow(query, id), &match)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -252,7 +443,7 @@ func (r *Repository) GetMatch(id string) (*models.Match, error) {
 }
 
 func (r *Repository) GetMatchesByRound(roundID string) ([]models.Match, error) {
-	query := `SELECT id, round_id, team1_id, team2_id, match_format_id, match_number, holes, status, points_available, team1_points, team2_points, created_at, updated_at FROM matches WHERE round_id = $1 ORDER BY match_number`
+	query := `SELECT ` + matchColumns + ` FROM matches WHERE round_id = $1 ORDER BY match_number`
 
 	rows, err := r.db.Query(query, roundID)
 	if err != nil {
@@ -263,12 +454,7 @@ func (r *Repository) GetMatchesByRound(roundID string) ([]models.Match, error) {
 	var matches []models.Match
 	for rows.Next() {
 		var match models.Match
-		err := rows.Scan(
-			&match.ID, &match.RoundID, &match.Team1ID, &match.Team2ID, &match.MatchFormatID,
-			&match.MatchNumber, &match.Holes, &match.Status, &match.PointsAvailable,
-			&match.Team1Points, &match.Team2Points, &match.CreatedAt, &match.UpdatedAt,
-		)
-		if err != nil {
+		if err := scanMatch(rows, &match); err != nil {
 			return nil, fmt.Errorf("failed to scan match: %w", err)
 		}
 		matches = append(matches, match)
@@ -277,23 +463,108 @@ func (r *Repository) GetMatchesByRound(roundID string) ([]models.Match, error) {
 	return matches, nil
 }
 
+// SetMatchPoints changes the points a match is worth; its team points are rescored separately
+func (r *Repository) SetMatchPoints(matchID string, pointsAvailable float64) error {
+	result, err := r.db.Exec(`UPDATE matches SET points_available = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, pointsAvailable, matchID)
+	if err != nil {
+		return fmt.Errorf("failed to set match points: %w", err)
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("match not found")
+	}
+
+	return nil
+}
+
+// ListMatches returns every match across all tournaments
+func (r *Repository) ListMatches() ([]models.Match, error) {
+	query := `SELECT ` + matchColumns + ` FROM matches ORDER BY round_id, match_number`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list matches: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []models.Match
+	for rows.Next() {
+		var match models.Match
+		if err := scanMatch(rows, &match); err != nil {
+			return nil, fmt.Errorf("failed to scan match: %w", err)
+		}
+		matches = append(matches, match)
+	}
+
+	return matches, nil
+}
+
+func playerTee(req *models.CreateMatchRequest, userID string) *string {
+	if teeID, ok := req.PlayerTees[userID]; ok {
+		return &teeID
+	}
+	return nil
+}
+
+func (r *Repository) AddMatchPlayer(matchID, userID, teamID string, position int, teeID *string) (*models.MatchPlayer, error) {
+	query := `
+		INSERT INTO match_players (match_id, user_id, team_id, player_order, tee_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+		RETURNING id, match_id, user_id, team_id, player_order, tee_id
+	`
+
+	var player models.MatchPlayer
+	err := r.db.QueryRow(query, matchID, userID, teamID, position, teeID).Scan(
+		&player.ID, &player.MatchID, &player.UserID, &player.TeamID, &player.Position, &player.TeeID,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to add match player: %w", err)
+	}
+
+	return &player, nil
+}
+
+func (r *Repository) GetMatchPlayers(matchID string) ([]models.MatchPlayer, error) {
+	query := `SELECT id, match_id, user_id, team_id, COALESCE(player_order, 0), tee_id FROM match_players WHERE match_id = $1 ORDER BY team_id, player_order`
+
+	rows, err := r.db.Query(query, matchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get match players: %w", err)
+	}
+	defer rows.Close()
+
+	var players []models.MatchPlayer
+	for rows.Next() {
+		var player models.MatchPlayer
+		err := rows.Scan(&player.ID, &player.MatchID, &player.UserID, &player.TeamID, &player.Position, &player.TeeID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan match player: %w", err)
+		}
+		players = append(players, player)
+	}
+
+	return players, nil
+}
+
 // ============================================
 // Score Repository Methods
 // ============================================
 
-func (r *Repository) SubmitScore(matchID, userID string, holeNumber, strokes int) (*models.Score, error) {
+// SubmitScore saves a player's raw strokes for a hole along with the adjusted score used for scoring
+func (r *Repository) SubmitScore(matchID, userID string, holeNumber, strokes int, adjustedStrokes *int) (*models.Score, error) {
 	query := `
-		INSERT INTO scores (match_id, user_id, hole_number, strokes, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		INSERT INTO scores (match_id, user_id, hole_number, strokes, adjusted_strokes, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
 		ON CONFLICT (match_id, user_id, hole_number) 
-		DO UPDATE SET strokes = EXCLUDED.strokes, updated_at = CURRENT_TIMESTAMP
-		RETURNING id, match_id, user_id, hole_number, strokes, created_at, updated_at
+		DO UPDATE SET strokes = EXCLUDED.strokes, adjusted_strokes = EXCLUDED.adjusted_strokes, updated_at = CURRENT_TIMESTAMP
+		RETURNING id, match_id, user_id, hole_number, strokes, adjusted_strokes, created_at, updated_at
 	`
 
 	var score models.Score
-	err := r.db.QueryRow(query, matchID, userID, holeNumber, strokes).Scan(
+	err := r.db.QueryRow(query, matchID, userID, holeNumber, strokes, adjustedStrokes).Scan(
 		&score.ID, &score.MatchID, &score.UserID, &score.HoleNumber,
-		&score.Strokes, &score.CreatedAt, &score.UpdatedAt,
+		&score.Strokes, &score.AdjustedStrokes, &score.CreatedAt, &score.UpdatedAt,
 	)
 
 	if err != nil {
@@ -303,8 +574,18 @@ func (r *Repository) SubmitScore(matchID, userID string, holeNumber, strokes int
 	return &score, nil
 }
 
+// UpdateAdjustedStrokes rewrites a score's capped strokes, leaving the strokes taken alone
+func (r *Repository) UpdateAdjustedStrokes(scoreID string, adjustedStrokes int) error {
+	query := `UPDATE scores SET adjusted_strokes = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+
+	if _, err := r.db.Exec(query, adjustedStrokes, scoreID); err != nil {
+		return fmt.Errorf("failed to update adjusted strokes: %w", err)
+	}
+	return nil
+}
+
 func (r *Repository) GetMatchScores(matchID string) ([]models.Score, error) {
-	query := `SELECT id, match_id, user_id, hole_number, strokes, created_at, updated_at FROM scores WHERE match_id = $1 ORDER BY hole_number, user_id`
+	query := `SELECT id, match_id, user_id, hole_number, strokes, adjusted_strokes, created_at, updated_at FROM scores WHERE match_id = $1 ORDER BY hole_number, user_id`
 
 	rows, err := r.db.Query(query, matchID)
 	if err != nil {
@@ -317,7 +598,7 @@ func (r *Repository) GetMatchScores(matchID string) ([]models.Score, error) {
 		var score models.Score
 		err := rows.Scan(
 			&score.ID, &score.MatchID, &score.UserID, &score.HoleNumber,
-			&score.Strokes, &score.CreatedAt, &score.UpdatedAt,
+			&score.Strokes, &score.AdjustedStrokes, &score.CreatedAt, &score.UpdatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan score: %w", err)
@@ -332,6 +613,30 @@ func (r *Repository) GetMatchScores(matchID string) ([]models.Score, error) {
 // User Repository Methods
 // ============================================
 
+func (r *Repository) GetUsersByIDs(ids []string) ([]models.User, error) {
+	query := `SELECT id, email, name, handicap, is_admin, created_at, updated_at FROM users WHERE id = ANY($1)`
+
+	rows, err := r.db.Query(query, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var user models.User
+		err := rows.Scan(
+			&user.ID, &user.Email, &user.Name, &user.Handicap, &user.IsAdmin, &user.CreatedAt, &user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
 func (r *Repository) CreateUser(email, name string, handicap *float64) (*models.User, error) {
 	query := `
 		INSERT INTO users (email, name, handicap, created_at, updated_at)
@@ -443,12 +748,266 @@ func (r *Repository) UpdateMatchStatus(matchID, status string) error {
 	return nil
 }
 
+// UpdateMatchResult stores the calculated status, team points and result text for a match
+func (r *Repository) UpdateMatchResult(matchID, status string, team1Points, team2Points float64, resultText string) error {
+	query := `
+		UPDATE matches
+		SET status = $1, team1_points = $2, team2_points = $3, result_text = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5
+	`
+
+	_, err := r.db.Exec(query, status, team1Points, team2Points, resultText, matchID)
+	if err != nil {
+		return fmt.Errorf("failed to update match result: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateMatchProbability saves the match's latest win probabilities
+func (r *Repository) UpdateMatchProbability(matchID string, probability *models.MatchProbability) error {
+	query := `
+		UPDATE matches
+		SET team1_win_probability = $1, halve_probability = $2, team2_win_probability = $3
+		WHERE id = $4
+	`
+
+	_, err := r.db.Exec(query, probability.Team1Win, probability.Halve, probability.Team2Win, matchID)
+	if err != nil {
+		return fmt.Errorf("failed to update match probability: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateMatchProgress saves how far the match has got and the current margin, for the live feed
+func (r *Repository) UpdateMatchProgress(matchID string, holesCompleted, team1Up int, scoringUnit string) error {
+	query := `
+		UPDATE matches
+		SET holes_completed = $1, team1_up = $2, scoring_unit = $3
+		WHERE id = $4
+	`
+
+	_, err := r.db.Exec(query, holesCompleted, team1Up, scoringUnit, matchID)
+	if err != nil {
+		return fmt.Errorf("failed to update match progress: %w", err)
+	}
+
+	return nil
+}
+
+// ============================================
+// Hole Result Repository Methods
+// ============================================
+
+// SaveHoleResults upserts the given hole results and removes stored results for
+// holes that are no longer complete
+func (r *Repository) SaveHoleResults(matchID string, results []models.HoleResult) error {
+	holeNumbers := make([]int64, 0, len(results))
+	for _, result := range results {
+		holeNumbers = append(holeNumbers, int64(result.HoleNumber))
+	}
+
+	_, err := r.db.Exec(`DELETE FROM hole_results WHERE match_id = $1 AND NOT (hole_number = ANY($2))`, matchID, pq.Array(holeNumbers))
+	if err != nil {
+		return fmt.Errorf("failed to clear stale hole results: %w", err)
+	}
+
+	query := `
+		INSERT INTO hole_results (match_id, hole_number, team1_score, team2_score, winner_team_id, team1_points, team2_points, contests, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT (match_id, hole_number)
+		DO UPDATE SET team1_score = EXCLUDED.team1_score, team2_score = EXCLUDED.team2_score,
+			winner_team_id = EXCLUDED.winner_team_id, team1_points = EXCLUDED.team1_points,
+			team2_points = EXCLUDED.team2_points, contests = EXCLUDED.contests, updated_at = CURRENT_TIMESTAMP
+	`
+	for _, result := range results {
+		_, err := r.db.Exec(query, matchID, result.HoleNumber, result.Team1Score, result.Team2Score,
+			result.WinnerTeamID, result.Team1Points, result.Team2Points, result.Contests)
+		if err != nil {
+			return fmt.Errorf("failed to save hole result: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *Repository) GetHoleResults(matchID string) ([]models.HoleResult, error) {
+	query := `SELECT id, match_id, hole_number, team1_score, team2_score, winner_team_id, team1_points, team2_points, contests, created_at, updated_at FROM hole_results WHERE match_id = $1 ORDER BY hole_number`
+
+	rows, err := r.db.Query(query, matchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hole results: %w", err)
+	}
+	defer rows.Close()
+
+	var results []models.HoleResult
+	for rows.Next() {
+		var result models.HoleResult
+		err := rows.Scan(
+			&result.ID, &result.MatchID, &result.HoleNumber, &result.Team1Score, &result.Team2Score,
+			&result.WinnerTeamID, &result.Team1Points, &result.Team2Points, &result.Contests, &result.CreatedAt, &result.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan hole result: %w", err)
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// ============================================
+// Press Repository Methods
+// ============================================
+
+func (r *Repository) CreatePress(matchID, teamID string, startHole int) (*models.Press, error) {
+	query := `
+		INSERT INTO match_presses (match_id, team_id, start_hole, created_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		RETURNING id, match_id, team_id, start_hole, created_at
+	`
+
+	var press models.Press
+	err := r.db.QueryRow(query, matchID, teamID, startHole).Scan(
+		&press.ID, &press.MatchID, &press.TeamID, &press.StartHole, &press.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create press: %w", err)
+	}
+
+	return &press, nil
+}
+
+func (r *Repository) GetMatchPresses(matchID string) ([]models.Press, error) {
+	query := `SELECT id, match_id, team_id, start_hole, created_at FROM match_presses WHERE match_id = $1 ORDER BY start_hole`
+
+	rows, err := r.db.Query(query, matchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get presses: %w", err)
+	}
+	defer rows.Close()
+
+	var presses []models.Press
+	for rows.Next() {
+		var press models.Press
+		if err := rows.Scan(&press.ID, &press.MatchID, &press.TeamID, &press.StartHole, &press.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan press: %w", err)
+		}
+		presses = append(presses, press)
+	}
+
+	return presses, nil
+}
+
+// ============================================
+// Concession Repository Methods
+// ============================================
+
+const concessionColumns = `id, match_id, concession_type, hole_number, conceding_team_id, user_id, strokes, recorded_by, created_at`
+
+func scanConcession(row rowScanner, concession *models.Concession) error {
+	return row.Scan(
+		&concession.ID, &concession.MatchID, &concession.Type, &concession.HoleNumber, &concession.ConcedingTeamID,
+		&concession.UserID, &concession.Strokes, &concession.RecordedBy, &concession.CreatedAt,
+	)
+}
+
+func (r *Repository) CreateConcession(concession *models.Concession) (*models.Concession, error) {
+	query := `
+		INSERT INTO match_concessions (match_id, concession_type, hole_number, conceding_team_id, user_id, strokes, recorded_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, CURRENT_TIMESTAMP)
+		RETURNING ` + concessionColumns
+
+	var created models.Concession
+	err := scanConcession(r.db.QueryRow(query, concession.MatchID, concession.Type, concession.HoleNumber,
+		concession.ConcedingTeamID, concession.UserID, concession.Strokes, concession.RecordedBy), &created)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create concession: %w", err)
+	}
+
+	return &created, nil
+}
+
+func (r *Repository) GetMatchConcessions(matchID string) ([]models.Concession, error) {
+	query := `SELECT ` + concessionColumns + ` FROM match_concessions WHERE match_id = $1 ORDER BY created_at`
+
+	rows, err := r.db.Query(query, matchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get concessions: %w", err)
+	}
+	defer rows.Close()
+
+	var concessions []models.Concession
+	for rows.Next() {
+		var concession models.Concession
+		if err := scanConcession(rows, &concession); err != nil {
+			return nil, fmt.Errorf("failed to scan concession: %w", err)
+		}
+		concessions = append(concessions, concession)
+	}
+
+	return concessions, nil
+}
+
+// ============================================
+// Match Event Repository Methods
+// ============================================
+
+// RecordMatchEvent adds an entry to a match's audit trail. details is stored as JSON.
+func (r *Repository) RecordMatchEvent(matchID, eventType string, holeNumber *int, userID, recordedBy *string, details interface{}) error {
+	payload, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("failed to encode match event: %w", err)
+	}
+
+	query := `
+		INSERT INTO match_events (match_id, event_type, hole_number, user_id, recorded_by, details, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP)
+	`
+	if _, err := r.db.Exec(query, matchID, eventType, holeNumber, userID, recordedBy, payload); err != nil {
+		return fmt.Errorf("failed to record match event: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetMatchEvents(matchID string) ([]models.MatchEvent, error) {
+	query := `
+		SELECT id, match_id, event_type, hole_number, user_id, recorded_by, details, created_at
+		FROM match_events WHERE match_id = $1 ORDER BY created_at, id
+	`
+
+	rows, err := r.db.Query(query, matchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get match events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.MatchEvent{}
+	for rows.Next() {
+		var event models.MatchEvent
+		var details []byte
+		err := rows.Scan(&event.ID, &event.MatchID, &event.EventType, &event.HoleNumber, &event.UserID,
+			&event.RecordedBy, &details, &event.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan match event: %w", err)
+		}
+		event.Details = details
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
 // ============================================
 // Match Format Repository Methods
 // ============================================
 
 func (r *Repository) GetAllMatchFormats() ([]map[string]interface{}, error) {
-	query := `SELECT id, name, description, players_per_side, scoring_type, created_at FROM match_formats ORDER BY name`
+	query := `SELECT id, name, description, players_per_side, scoring_type, config, created_at FROM match_formats ORDER BY name`
 
 	rows, err := r.db.Query(query)
 	if err != nil {
@@ -460,8 +1019,9 @@ func (r *Repository) GetAllMatchFormats() ([]map[string]interface{}, error) {
 	for rows.Next() {
 		var id, name, description, scoringType, createdAt string
 		var playersPerSide int
+		var config models.FormatConfig
 
-		err := rows.Scan(&id, &name, &description, &playersPerSide, &scoringType, &createdAt)
+		err := rows.Scan(&id, &name, &description, &playersPerSide, &scoringType, &config, &createdAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan match format: %w", err)
 		}
@@ -472,6 +1032,7 @@ func (r *Repository) GetAllMatchFormats() ([]map[string]interface{}, error) {
 			"description":      description,
 			"players_per_side": playersPerSide,
 			"scoring_type":     scoringType,
+			"config":           config,
 			"created_at":       createdAt,
 		}
 		formats = append(formats, format)
@@ -480,6 +1041,62 @@ func (r *Repository) GetAllMatchFormats() ([]map[string]interface{}, error) {
 	return formats, nil
 }
 
+func (r *Repository) GetMatchFormat(id string) (*models.MatchFormatDefinition, error) {
+	query := `SELECT id, name, description, players_per_side, scoring_type, config, created_at FROM match_formats WHERE id = $1`
+
+	var format models.MatchFormatDefinition
+	err := r.db.QueryRow(query, id).Scan(
+		&format.ID, &format.Name, &format.Description, &format.PlayersPerSide, &format.ScoringType, &format.Config, &format.CreatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("match format not found")
+		}
+		return nil, fmt.Errorf("failed to get match format: %w", err)
+	}
+
+	return &format, nil
+}
+
+// GetMatchFormatByName looks a format up by its unique name, e.g. "2v2 Scramble"
+func (r *Repository) GetMatchFormatByName(name string) (*models.MatchFormatDefinition, error) {
+	query := `SELECT id, name, description, players_per_side, scoring_type, config, created_at FROM match_formats WHERE name = $1`
+
+	var format models.MatchFormatDefinition
+	err := r.db.QueryRow(query, name).Scan(
+		&format.ID, &format.Name, &format.Description, &format.PlayersPerSide, &format.ScoringType, &format.Config, &format.CreatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("match format not found")
+		}
+		return nil, fmt.Errorf("failed to get match format: %w", err)
+	}
+
+	return &format, nil
+}
+
+func (r *Repository) CreateMatchFormat(req *models.CreateMatchFormatRequest) (*models.MatchFormatDefinition, error) {
+	query := `
+		INSERT INTO match_formats (name, description, players_per_side, scoring_type, config, created_at)
+		VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+		RETURNING id, name, description, players_per_side, scoring_type, config, created_at
+	`
+
+	var format models.MatchFormatDefinition
+	err := r.db.QueryRow(query, req.Name, req.Description, req.PlayersPerSide, req.ScoringType, req.Config).Scan(
+		&format.ID, &format.Name, &format.Description, &format.PlayersPerSide, &format.ScoringType, &format.Config, &format.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create match format: %w", err)
+	}
+
+	return &format, nil
+}
+
 // ============================================
 // Group Repository Methods
 // ============================================
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"mayhamapi/models"
+)
+
+// ============================================
+// Skins Repository Methods
+// ============================================
+
+func (r *Repository) GetSkinsGame(roundID string) (*models.SkinsGame, error) {
+	query := `
+		SELECT round_id, mode, carryovers, validation_birdie, pot, created_at, updated_at
+		FROM skins_games WHERE round_id = $1
+	`
+
+	var game models.SkinsGame
+	err := r.db.QueryRow(query, roundID).Scan(
+		&game.RoundID, &game.Mode, &game.Carryovers, &game.ValidationBirdie, &game.Pot,
+		&game.CreatedAt, &game.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("skins game not found")
+		}
+		return nil, fmt.Errorf("failed to get skins game: %w", err)
+	}
+
+	return &game, nil
+}
+
+// SaveSkinsGame creates or replaces the skins settings for a round
+func (r *Repository) SaveSkinsGame(roundID string, req *models.SetSkinsGameRequest) (*models.SkinsGame, error) {
+	carryovers := true
+	if req.Carryovers != nil {
+		carryovers = *req.Carryovers
+	}
+
+	query := `
+		INSERT INTO skins_games (round_id, mode, carryovers, validation_birdie, pot, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT (round_id) DO UPDATE SET
+			mode = EXCLUDED.mode,
+			carryovers = EXCLUDED.carryovers,
+			validation_birdie = EXCLUDED.validation_birdie,
+			pot = EXCLUDED.pot,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING round_id, mode, carryovers, validation_birdie, pot, created_at, updated_at
+	`
+
+	var game models.SkinsGame
+	err := r.db.QueryRow(query, roundID, req.Mode, carryovers, req.ValidationBirdie, req.Pot).Scan(
+		&game.RoundID, &game.Mode, &game.Carryovers, &game.ValidationBirdie, &game.Pot,
+		&game.CreatedAt, &game.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to save skins game: %w", err)
+	}
+
+	return &game, nil
+}
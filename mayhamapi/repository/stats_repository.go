@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"fmt"
+
+	"mayhamapi/models"
+
+	"github.com/lib/pq"
+)
+
+// ============================================
+// Player Stats Repository Methods
+// ============================================
+
+const playerStatsColumns = `id, tournament_id, user_id, team_id, matches_played, matches_won, matches_lost, matches_tied,
+	points_won, points_lost, holes_won, holes_lost, holes_tied, updated_at`
+
+func scanPlayerStats(row rowScanner) (*models.PlayerStats, error) {
+	var stats models.PlayerStats
+	err := row.Scan(
+		&stats.ID, &stats.TournamentID, &stats.UserID, &stats.TeamID, &stats.MatchesPlayed, &stats.MatchesWon,
+		&stats.MatchesLost, &stats.MatchesTied, &stats.PointsWon, &stats.PointsLost, &stats.HolesWon,
+		&stats.HolesLost, &stats.HolesTied, &stats.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// ReplacePlayerStats swaps a tournament's player_stats rows for the given set.
+// Run it in a transaction so readers never see the tournament without stats.
+func (r *Repository) ReplacePlayerStats(tournamentID string, stats []models.PlayerStats) error {
+	_, err := r.db.Exec(`DELETE FROM player_stats WHERE tournament_id = $1`, tournamentID)
+	if err != nil {
+		return fmt.Errorf("failed to clear player stats: %w", err)
+	}
+
+	query := `
+		INSERT INTO player_stats (tournament_id, user_id, team_id, matches_played, matches_won, matches_lost, matches_tied,
+			points_won, points_lost, holes_won, holes_lost, holes_tied, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, CURRENT_TIMESTAMP)
+	`
+	for _, s := range stats {
+		_, err := r.db.Exec(query, tournamentID, s.UserID, s.TeamID, s.MatchesPlayed, s.MatchesWon, s.MatchesLost,
+			s.MatchesTied, s.PointsWon, s.PointsLost, s.HolesWon, s.HolesLost, s.HolesTied)
+		if err != nil {
+			return fmt.Errorf("failed to save player stats: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// UpsertPlayerStats writes player_stats rows, replacing any the players already have in their tournament
+func (r *Repository) UpsertPlayerStats(stats []models.PlayerStats) error {
+	query := `
+		INSERT INTO player_stats (tournament_id, user_id, team_id, matches_played, matches_won, matches_lost, matches_tied,
+			points_won, points_lost, holes_won, holes_lost, holes_tied, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, CURRENT_TIMESTAMP)
+		ON CONFLICT (tournament_id, user_id) DO UPDATE SET
+			team_id = EXCLUDED.team_id, matches_played = EXCLUDED.matches_played, matches_won = EXCLUDED.matches_won,
+			matches_lost = EXCLUDED.matches_lost, matches_tied = EXCLUDED.matches_tied, points_won = EXCLUDED.points_won,
+			points_lost = EXCLUDED.points_lost, holes_won = EXCLUDED.holes_won, holes_lost = EXCLUDED.holes_lost,
+			holes_tied = EXCLUDED.holes_tied, updated_at = CURRENT_TIMESTAMP
+	`
+	for _, s := range stats {
+		_, err := r.db.Exec(query, s.TournamentID, s.UserID, s.TeamID, s.MatchesPlayed, s.MatchesWon, s.MatchesLost,
+			s.MatchesTied, s.PointsWon, s.PointsLost, s.HolesWon, s.HolesLost, s.HolesTied)
+		if err != nil {
+			return fmt.Errorf("failed to save player stats: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetTournamentPlayerMatches returns a tournament's matches, in any state, that any of the players
+// is rostered for. Matches without assigned players count for everyone on either team.
+func (r *Repository) GetTournamentPlayerMatches(tournamentID string, userIDs []string) ([]models.Match, error) {
+	query := `SELECT ` + matchColumns + ` FROM matches m
+		WHERE m.round_id IN (SELECT r.id FROM rounds r WHERE r.tournament_id = $1)
+			AND (
+				EXISTS (SELECT 1 FROM match_players mp WHERE mp.match_id = m.id AND mp.user_id = ANY($2))
+				OR (
+					NOT EXISTS (SELECT 1 FROM match_players mp WHERE mp.match_id = m.id)
+					AND EXISTS (SELECT 1 FROM team_members tm WHERE tm.user_id = ANY($2) AND tm.team_id IN (m.team1_id, m.team2_id))
+				)
+			)
+		ORDER BY m.created_at`
+
+	rows, err := r.db.Query(query, tournamentID, pq.Array(userIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get player matches: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []models.Match
+	for rows.Next() {
+		var match models.Match
+		if err := scanMatch(rows, &match); err != nil {
+			return nil, fmt.Errorf("failed to scan match: %w", err)
+		}
+		matches = append(matches, match)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating player matches: %w", err)
+	}
+
+	return matches, nil
+}
+
+func (r *Repository) GetPlayerStatsByTournament(tournamentID string) ([]models.PlayerStats, error) {
+	query := `SELECT ` + playerStatsColumns + ` FROM player_stats WHERE tournament_id = $1`
+
+	rows, err := r.db.Query(query, tournamentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get player stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []models.PlayerStats
+	for rows.Next() {
+		row, err := scanPlayerStats(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan player stats: %w", err)
+		}
+		stats = append(stats, *row)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating player stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// groupTournaments selects the tournaments in the groups both $1 and the viewer $3 belong to,
+// narrowed to $2 when it's set
+const groupTournaments = `
+	SELECT t.id FROM tournaments t
+	WHERE t.group_id IN (SELECT group_id FROM group_members WHERE user_id = $1)
+		AND t.group_id IN (SELECT group_id FROM group_members WHERE user_id = $3)
+		AND ($2::uuid IS NULL OR t.id = $2::uuid)
+`
+
+// GetPlayerStatsByUser returns a player's stats rows across the tournaments in the groups they
+// share with viewerID, or just one tournament's when tournamentID is set
+func (r *Repository) GetPlayerStatsByUser(userID, viewerID string, tournamentID *string) ([]models.PlayerStats, error) {
+	query := `SELECT ` + playerStatsColumns + ` FROM player_stats
+		WHERE user_id = $1 AND tournament_id IN (` + groupTournaments + `)
+		ORDER BY updated_at`
+
+	rows, err := r.db.Query(query, userID, tournamentID, viewerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get player stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []models.PlayerStats{}
+	for rows.Next() {
+		row, err := scanPlayerStats(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan player stats: %w", err)
+		}
+		stats = append(stats, *row)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating player stats: %w", err)
+	}
+
+	return stats, nil
+}
+
+// GetPlayerMatches returns the completed matches a player was rostered for across the tournaments
+// in the groups they share with viewerID, or just one tournament's when tournamentID is set.
+// Matches without assigned players count for everyone on either team.
+func (r *Repository) GetPlayerMatches(userID, viewerID string, tournamentID *string) ([]models.Match, error) {
+	query := `SELECT ` + matchColumns + ` FROM matches m
+		WHERE m.status = 'completed'
+			AND m.round_id IN (SELECT r.id FROM rounds r WHERE r.tournament_id IN (` + groupTournaments + `))
+			AND (
+				EXISTS (SELECT 1 FROM match_players mp WHERE mp.match_id = m.id AND mp.user_id = $1)
+				OR (
+					NOT EXISTS (SELECT 1 FROM match_players mp WHERE mp.match_id = m.id)
+					AND EXISTS (SELECT 1 FROM team_members tm WHERE tm.user_id = $1 AND tm.team_id IN (m.team1_id, m.team2_id))
+				)
+			)
+		ORDER BY m.created_at`
+
+	rows, err := r.db.Query(query, userID, tournamentID, viewerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get player matches: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []models.Match
+	for rows.Next() {
+		var match models.Match
+		if err := scanMatch(rows, &match); err != nil {
+			return nil, fmt.Errorf("failed to scan match: %w", err)
+		}
+		matches = append(matches, match)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating player matches: %w", err)
+	}
+
+	return matches, nil
+}
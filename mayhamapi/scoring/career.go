@@ -0,0 +1,237 @@
+package scoring
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+
+	"mayhamapi/models"
+)
+
+// Record is a won-lost-halved record over completed matches
+type Record struct {
+	MatchesPlayed int     `json:"matches_played"`
+	Won           int     `json:"won"`
+	Lost          int     `json:"lost"`
+	Halved        int     `json:"halved"`
+	Summary       string  `json:"summary"` // W-L-H, e.g. "5-2-1"
+	PointsWon     float64 `json:"points_won"`
+	PointsLost    float64 `json:"points_lost"`
+	// WinPercentage counts a halved match as half a win
+	WinPercentage float64 `json:"win_percentage"`
+}
+
+// FormatRecord is a player's record in one match format
+type FormatRecord struct {
+	FormatID    string             `json:"format_id"`
+	Name        string             `json:"name"`
+	ScoringType models.MatchFormat `json:"scoring_type"`
+	Record
+}
+
+// PlayerRecord is a player's record alongside a partner, or against an opponent
+type PlayerRecord struct {
+	User models.PlayerSummary `json:"user"`
+	Record
+}
+
+// CareerStats is a player's record across the tournaments in the groups they share with the viewer
+type CareerStats struct {
+	User         models.PlayerSummary `json:"user"`
+	TournamentID *string              `json:"tournament_id,omitempty"`
+	Overall      Record               `json:"overall"`
+	ByFormat     []FormatRecord       `json:"by_format"`
+	Partners     []PlayerRecord       `json:"partners"`
+	Opponents    []PlayerRecord       `json:"opponents"`
+	// Tournaments holds the player's stats row for each tournament they've been on a team in
+	Tournaments []models.PlayerStats `json:"tournaments"`
+}
+
+// CalculateCareerStats builds a player's record from every completed match they've played in the
+// tournaments of the groups they share with viewerID, or in one tournament when tournamentID is set.
+// Each match is credited to the player's overall record, its format, every partner on their side
+// and every opponent. Matches without players on both sides yet are left out.
+func (s *ScoringService) CalculateCareerStats(userID, viewerID string, tournamentID *string) (*CareerStats, error) {
+	users, err := s.repo.GetUsersByIDs([]string{userID})
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, fmt.Errorf("user not found")
+	}
+	if tournamentID != nil {
+		tournament, err := s.repo.GetTournament(*tournamentID)
+		if err != nil {
+			return nil, err
+		}
+		groups, err := s.repo.GetUserGroups(viewerID)
+		if err != nil {
+			return nil, err
+		}
+		isMember := false
+		for _, group := range groups {
+			if group.ID == tournament.GroupID {
+				isMember = true
+				break
+			}
+		}
+		if !isMember {
+			return nil, fmt.Errorf("not a member of the tournament's group")
+		}
+	}
+
+	matches, err := s.repo.GetPlayerMatches(userID, viewerID, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+
+	tally := newCareerTally(userID)
+	for i := range matches {
+		match := &matches[i]
+		roster, err := s.loadRoster(match)
+		if errors.Is(err, errNoRoster) {
+			log.Printf("Skipping match %s in career stats for %s: %v", match.ID, userID, err)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		tally.addMatch(match, roster)
+	}
+
+	stats := &CareerStats{
+		User:         models.PlayerSummary{ID: users[0].ID, Name: users[0].Name, Handicap: users[0].Handicap},
+		TournamentID: tournamentID,
+		Overall:      careerRecord(tally.overall),
+		ByFormat:     []FormatRecord{},
+	}
+
+	for formatID, record := range tally.formats {
+		format, err := s.repo.GetMatchFormat(formatID)
+		if err != nil {
+			return nil, err
+		}
+		stats.ByFormat = append(stats.ByFormat, FormatRecord{
+			FormatID:    formatID,
+			Name:        format.Name,
+			ScoringType: format.ScoringType,
+			Record:      careerRecord(record),
+		})
+	}
+	sort.Slice(stats.ByFormat, func(i, j int) bool {
+		a, b := stats.ByFormat[i], stats.ByFormat[j]
+		if a.MatchesPlayed != b.MatchesPlayed {
+			return a.MatchesPlayed > b.MatchesPlayed
+		}
+		return a.Name < b.Name
+	})
+
+	if stats.Partners, err = s.playerRecords(tally.partners); err != nil {
+		return nil, err
+	}
+	if stats.Opponents, err = s.playerRecords(tally.opponents); err != nil {
+		return nil, err
+	}
+
+	if stats.Tournaments, err = s.repo.GetPlayerStatsByUser(userID, viewerID, tournamentID); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// careerTally adds up one player's matches overall, by format ID and by partner and opponent user ID
+type careerTally struct {
+	userID    string
+	overall   *standingRecord
+	formats   map[string]*standingRecord
+	partners  map[string]*standingRecord
+	opponents map[string]*standingRecord
+}
+
+func newCareerTally(userID string) *careerTally {
+	return &careerTally{
+		userID:    userID,
+		overall:   &standingRecord{},
+		formats:   make(map[string]*standingRecord),
+		partners:  make(map[string]*standingRecord),
+		opponents: make(map[string]*standingRecord),
+	}
+}
+
+// addMatch credits a completed match to the player's records, if they played in it
+func (t *careerTally) addMatch(match *models.Match, roster *MatchRoster) {
+	userSide := roster.side(t.userID)
+	if userSide == 0 {
+		return
+	}
+	side, other := roster.Team1, roster.Team2
+	points, against := match.Team1Points, match.Team2Points
+	if userSide == 2 {
+		side, other = other, side
+		points, against = against, points
+	}
+
+	record := func(records map[string]*standingRecord, key string) *standingRecord {
+		if records[key] == nil {
+			records[key] = &standingRecord{}
+		}
+		return records[key]
+	}
+	t.overall.addMatch(points, against)
+	record(t.formats, match.MatchFormatID).addMatch(points, against)
+	for _, partnerID := range side {
+		if partnerID != t.userID {
+			record(t.partners, partnerID).addMatch(points, against)
+		}
+	}
+	for _, opponentID := range other {
+		record(t.opponents, opponentID).addMatch(points, against)
+	}
+}
+
+// playerRecords names each partner or opponent and lists the ones played with or against most first
+func (s *ScoringService) playerRecords(records map[string]*standingRecord) ([]PlayerRecord, error) {
+	list := make([]PlayerRecord, 0, len(records))
+	if len(records) == 0 {
+		return list, nil
+	}
+
+	userIDs := make([]string, 0, len(records))
+	for userID := range records {
+		userIDs = append(userIDs, userID)
+	}
+	users, err := s.repo.GetUsersByIDs(userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, user := range users {
+		list = append(list, PlayerRecord{
+			User:   models.PlayerSummary{ID: user.ID, Name: user.Name, Handicap: user.Handicap},
+			Record: careerRecord(records[user.ID]),
+		})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].MatchesPlayed != list[j].MatchesPlayed {
+			return list[i].MatchesPlayed > list[j].MatchesPlayed
+		}
+		return list[i].User.Name < list[j].User.Name
+	})
+	return list, nil
+}
+
+func careerRecord(r *standingRecord) Record {
+	return Record{
+		MatchesPlayed: r.matchesPlayed(),
+		Won:           r.matchesWon,
+		Lost:          r.matchesLost,
+		Halved:        r.tied,
+		Summary:       fmt.Sprintf("%d-%d-%d", r.matchesWon, r.matchesLost, r.tied),
+		PointsWon:     r.pointsWon,
+		PointsLost:    r.pointsLost,
+		WinPercentage: math.Round(r.winPercentage()*1000) / 1000,
+	}
+}
@@ -0,0 +1,74 @@
+package scoring
+
+import (
+	"reflect"
+	"testing"
+
+	"mayhamapi/models"
+)
+
+func TestCareerTally(t *testing.T) {
+	fourballs := &MatchRoster{Team1: []string{"me", "pal"}, Team2: []string{"x", "y"}}
+	swapped := &MatchRoster{Team1: []string{"x", "z"}, Team2: []string{"me", "pal"}}
+	singles := &MatchRoster{Team1: []string{"me"}, Team2: []string{"x"}}
+	without := &MatchRoster{Team1: []string{"pal"}, Team2: []string{"y"}}
+
+	tally := newCareerTally("me")
+	tally.addMatch(&models.Match{MatchFormatID: "bb", Team1Points: 1}, fourballs)
+	tally.addMatch(&models.Match{MatchFormatID: "bb", Team1Points: 1}, swapped) // lost, playing as team 2
+	tally.addMatch(&models.Match{MatchFormatID: "mp", Team1Points: 0.5, Team2Points: 0.5}, singles)
+	tally.addMatch(&models.Match{MatchFormatID: "mp", Team1Points: 1}, without) // not the player's match
+
+	summary := func(records map[string]*standingRecord) map[string]string {
+		summaries := make(map[string]string, len(records))
+		for key, record := range records {
+			summaries[key] = careerRecord(record).Summary
+		}
+		return summaries
+	}
+	if got := careerRecord(tally.overall).Summary; got != "1-1-1" {
+		t.Errorf("overall = %s, want 1-1-1", got)
+	}
+	if got, want := summary(tally.formats), map[string]string{"bb": "1-1-0", "mp": "0-0-1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("by format = %v, want %v", got, want)
+	}
+	if got, want := summary(tally.partners), map[string]string{"pal": "1-1-0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("partners = %v, want %v", got, want)
+	}
+	if got, want := summary(tally.opponents), map[string]string{"x": "1-1-1", "y": "1-0-0", "z": "0-1-0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("opponents = %v, want %v", got, want)
+	}
+}
+
+func TestCareerRecord(t *testing.T) {
+	tests := []struct {
+		name   string
+		record standingRecord
+		want   Record
+	}{
+		{"no matches", standingRecord{}, Record{Summary: "0-0-0"}},
+		{
+			"won, lost and halved",
+			standingRecord{matchesWon: 5, matchesLost: 2, tied: 1, pointsWon: 5.5, pointsLost: 2.5},
+			Record{MatchesPlayed: 8, Won: 5, Lost: 2, Halved: 1, Summary: "5-2-1", PointsWon: 5.5, PointsLost: 2.5, WinPercentage: 0.688},
+		},
+		{
+			"halves count as half a win",
+			standingRecord{tied: 2, pointsWon: 1, pointsLost: 1},
+			Record{MatchesPlayed: 2, Halved: 2, Summary: "0-0-2", PointsWon: 1, PointsLost: 1, WinPercentage: 0.5},
+		},
+		{
+			"win percentage rounded to three places",
+			standingRecord{matchesWon: 1, matchesLost: 2, pointsWon: 1, pointsLost: 2},
+			Record{MatchesPlayed: 3, Won: 1, Lost: 2, Summary: "1-2-0", PointsWon: 1, PointsLost: 2, WinPercentage: 0.333},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := careerRecord(&tt.record); got != tt.want {
+				t.Errorf("careerRecord = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
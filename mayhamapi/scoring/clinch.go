@@ -0,0 +1,243 @@
+package scoring
+
+import (
+	"fmt"
+	"math"
+
+	"mayhamapi/models"
+)
+
+// CupTeam is one side's position in the race for the cup
+type CupTeam struct {
+	TeamID string  `json:"team_id"`
+	Name   string  `json:"name"`
+	Points float64 `json:"points"` // banked from completed matches
+	// MaxPoints is what the team finishes on if it wins every unfinished match
+	MaxPoints float64 `json:"max_points"`
+	// MagicNumber is how many of the points still available the team needs to clinch the cup:
+	// zero once it has, nil once it no longer can. The defending team's counts retaining on a tie.
+	MagicNumber *float64 `json:"magic_number"`
+	// RetainNumber is the points the defending team needs to be sure of at least a tie
+	RetainNumber *float64 `json:"retain_number,omitempty"`
+	Defending    bool     `json:"defending"`
+	Eliminated   bool     `json:"eliminated"`
+	// WinProbability is the chance of ending up with the cup, by winning or retaining it
+	WinProbability float64 `json:"win_probability"`
+}
+
+// CupStatus says whether a two-team tournament's cup has been decided, and what each team needs if not
+type CupStatus struct {
+	TournamentID     string  `json:"tournament_id"`
+	TargetPoints     float64 `json:"target_points"`
+	TotalPoints      float64 `json:"total_points"`
+	PointsRemaining  float64 `json:"points_remaining"`
+	MatchesRemaining int     `json:"matches_remaining"`
+	DefendingTeamID  *string `json:"defending_team_id,omitempty"`
+	// RetainsOnTie is set when there's a defending team, which keeps the cup on a tie
+	RetainsOnTie bool      `json:"retains_on_tie"`
+	Teams        []CupTeam `json:"teams"`
+	Clinched     bool      `json:"clinched"`
+	WinnerTeamID *string   `json:"winner_team_id,omitempty"`
+	// Retained is set when the defending team has clinched a tie but not the target score;
+	// Shared when neither team can reach the target and there's no defending team
+	Retained         bool    `json:"retained"`
+	Shared           bool    `json:"shared"`
+	ShareProbability float64 `json:"share_probability"`
+}
+
+// CalculateCupStatus works out the cup standings from the points banked in completed
+// matches and the points still available in unfinished ones
+func (s *ScoringService) CalculateCupStatus(tournamentID string) (*CupStatus, error) {
+	tournament, err := s.repo.GetTournament(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+
+	teams, err := s.repo.GetTeamsByTournament(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	if len(teams) != 2 {
+		return nil, fmt.Errorf("cup needs exactly two teams")
+	}
+
+	matches, err := s.tournamentMatches(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := cupStatus(tournament, teams, matches)
+	s.cupProbabilities(status, matches)
+	return status, nil
+}
+
+// ClinchedByMatch returns the cup status when completing the given match is what decided
+// the cup, or nil when the cup is still open or had already been decided without it
+func (s *ScoringService) ClinchedByMatch(matchID string) (*CupStatus, error) {
+	match, err := s.repo.GetMatch(matchID)
+	if err != nil {
+		return nil, err
+	}
+	if match.Status != "completed" {
+		return nil, nil
+	}
+
+	round, err := s.repo.GetRound(match.RoundID)
+	if err != nil {
+		return nil, err
+	}
+	tournament, err := s.repo.GetTournament(round.TournamentID)
+	if err != nil {
+		return nil, err
+	}
+	teams, err := s.repo.GetTeamsByTournament(tournament.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(teams) != 2 {
+		return nil, nil
+	}
+
+	matches, err := s.tournamentMatches(tournament.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	after := cupStatus(tournament, teams, matches)
+	if !after.Clinched {
+		return nil, nil
+	}
+
+	// Replay the standings with this match still in play
+	before := make([]models.Match, len(matches))
+	copy(before, matches)
+	for i := range before {
+		if before[i].ID == matchID {
+			before[i].Status = "in_progress"
+			before[i].Team1Points, before[i].Team2Points = 0, 0
+		}
+	}
+	if cupStatus(tournament, teams, before).Clinched {
+		return nil, nil
+	}
+
+	s.cupProbabilities(after, matches)
+	return after, nil
+}
+
+// tournamentMatches returns every match in every round of a tournament
+func (s *ScoringService) tournamentMatches(tournamentID string) ([]models.Match, error) {
+	rounds, err := s.repo.GetRoundsByTournament(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []models.Match
+	for _, round := range rounds {
+		roundMatches, err := s.repo.GetMatchesByRound(round.ID)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, roundMatches...)
+	}
+	return matches, nil
+}
+
+func cupStatus(tournament *models.Tournament, teams []models.Team, matches []models.Match) *CupStatus {
+	status := &CupStatus{
+		TournamentID:    tournament.ID,
+		DefendingTeamID: tournament.DefendingTeamID,
+		RetainsOnTie:    tournament.DefendingTeamID != nil,
+	}
+
+	points := make(map[string]float64, len(teams))
+	for _, match := range matches {
+		status.TotalPoints += match.PointsAvailable
+		if match.Status != "completed" {
+			status.PointsRemaining += match.PointsAvailable
+			status.MatchesRemaining++
+			continue
+		}
+		points[match.Team1ID] += match.Team1Points
+		points[match.Team2ID] += match.Team2Points
+	}
+
+	// Without a set target the cup goes to whoever gets more than half the points,
+	// which are won in half-point steps
+	status.TargetPoints = math.Floor(status.TotalPoints+1) / 2
+	if tournament.TargetPoints != nil {
+		status.TargetPoints = *tournament.TargetPoints
+	}
+
+	for _, team := range teams {
+		status.Teams = append(status.Teams, CupTeam{
+			TeamID:    team.ID,
+			Name:      team.Name,
+			Points:    points[team.ID],
+			MaxPoints: points[team.ID] + status.PointsRemaining,
+			Defending: tournament.DefendingTeamID != nil && *tournament.DefendingTeamID == team.ID,
+		})
+	}
+
+	for i := range status.Teams {
+		team, other := &status.Teams[i], &status.Teams[1-i]
+		magic := math.Max(status.TargetPoints-team.Points, 0)
+		if team.Defending {
+			// Retaining needs enough points that the other side can't reach the target
+			retain := 0.0
+			if other.MaxPoints >= status.TargetPoints {
+				retain = math.Floor((other.MaxPoints-status.TargetPoints)*2)/2 + 0.5
+			}
+			team.RetainNumber = &retain
+			magic = math.Min(magic, retain)
+		}
+		if magic <= status.PointsRemaining {
+			team.MagicNumber = &magic
+		} else {
+			team.Eliminated = true
+		}
+	}
+
+	decideCup(status)
+	return status
+}
+
+// decideCup marks the cup clinched once the result can't change: a team has reached the
+// target, or neither can and the defending team retains (or, without one, the cup is shared)
+func decideCup(status *CupStatus) {
+	team1, team2 := &status.Teams[0], &status.Teams[1]
+	switch {
+	case team1.Points >= status.TargetPoints || team2.Points >= status.TargetPoints:
+		winner := team1
+		if team2.Points > team1.Points {
+			winner = team2
+		} else if team2.Points == team1.Points {
+			// Only possible with a target at or below half the points on offer
+			status.Clinched = true
+			status.retainOrShare()
+			return
+		}
+		status.Clinched = true
+		status.WinnerTeamID = &winner.TeamID
+	case team1.MaxPoints < status.TargetPoints && team2.MaxPoints < status.TargetPoints:
+		status.Clinched = true
+		status.retainOrShare()
+	case status.DefendingTeamID != nil:
+		for _, team := range status.Teams {
+			if !team.Defending && team.MaxPoints < status.TargetPoints {
+				status.Clinched = true
+				status.retainOrShare()
+			}
+		}
+	}
+}
+
+// retainOrShare settles a cup neither team has won outright
+func (status *CupStatus) retainOrShare() {
+	if status.DefendingTeamID != nil {
+		status.WinnerTeamID = status.DefendingTeamID
+		status.Retained = true
+		return
+	}
+	status.Shared = true
+}
@@ -0,0 +1,172 @@
+package scoring
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"mayhamapi/models"
+)
+
+// cupMatches gives one-point matches between t1 and t2: the completed ones with the given
+// points for each side, then the given number still to play
+func cupMatches(completed [][2]float64, remaining int) []models.Match {
+	var matches []models.Match
+	for _, points := range completed {
+		matches = append(matches, models.Match{Team1ID: "t1", Team2ID: "t2", PointsAvailable: 1, Status: "completed",
+			Team1Points: points[0], Team2Points: points[1]})
+	}
+	for i := 0; i < remaining; i++ {
+		matches = append(matches, models.Match{Team1ID: "t1", Team2ID: "t2", PointsAvailable: 1, Status: "scheduled"})
+	}
+	return matches
+}
+
+// repeatResult repeats one match result n times
+func repeatResult(n int, points [2]float64) [][2]float64 {
+	results := make([][2]float64, n)
+	for i := range results {
+		results[i] = points
+	}
+	return results
+}
+
+// outcome is the part of a cup status the tests check
+type outcome struct {
+	target       float64
+	magic        [2]*float64
+	retain       [2]*float64
+	eliminated   [2]bool
+	clinched     bool
+	winnerTeamID *string
+	retained     bool
+	shared       bool
+}
+
+func TestCupStatus(t *testing.T) {
+	win, loss, half := [2]float64{1, 0}, [2]float64{0, 1}, [2]float64{0.5, 0.5}
+	fptr := func(f float64) *float64 { return &f }
+	sptr := func(s string) *string { return &s }
+
+	tests := []struct {
+		name      string
+		target    *float64
+		defending *string
+		completed [][2]float64
+		remaining int
+		want      outcome
+	}{
+		{
+			name:      "open",
+			completed: [][2]float64{win, win, loss},
+			remaining: 7,
+			want:      outcome{target: 5.5, magic: [2]*float64{fptr(3.5), fptr(4.5)}},
+		},
+		{
+			name:      "won on reaching the target",
+			completed: repeatResult(6, win),
+			remaining: 4,
+			want: outcome{target: 5.5, magic: [2]*float64{fptr(0), nil}, eliminated: [2]bool{false, true},
+				clinched: true, winnerTeamID: sptr("t1")},
+		},
+		{
+			name:      "half points count toward the target",
+			completed: append(repeatResult(5, win), half),
+			remaining: 4,
+			want: outcome{target: 5.5, magic: [2]*float64{fptr(0), nil}, eliminated: [2]bool{false, true},
+				clinched: true, winnerTeamID: sptr("t1")},
+		},
+		{
+			name:      "tied without a defending team is shared",
+			completed: append(repeatResult(5, win), repeatResult(5, loss)...),
+			want: outcome{target: 5.5, eliminated: [2]bool{true, true},
+				clinched: true, shared: true},
+		},
+		{
+			name:      "defending team retains on a tie",
+			defending: sptr("t2"),
+			completed: append(repeatResult(5, win), repeatResult(5, loss)...),
+			want: outcome{target: 5.5, magic: [2]*float64{nil, fptr(0)}, retain: [2]*float64{nil, fptr(0)},
+				eliminated: [2]bool{true, false}, clinched: true, winnerTeamID: sptr("t2"), retained: true},
+		},
+		{
+			name:      "defending team retains before the last match",
+			defending: sptr("t2"),
+			completed: repeatResult(5, loss),
+			remaining: 5,
+			want: outcome{target: 5.5, magic: [2]*float64{nil, fptr(0)}, retain: [2]*float64{nil, fptr(0)},
+				eliminated: [2]bool{true, false}, clinched: true, winnerTeamID: sptr("t2"), retained: true},
+		},
+		{
+			name:      "defending team's retain number",
+			defending: sptr("t1"),
+			completed: [][2]float64{loss, loss},
+			remaining: 8,
+			want:      outcome{target: 5.5, magic: [2]*float64{fptr(5), fptr(3.5)}, retain: [2]*float64{fptr(5), nil}},
+		},
+		{
+			name:      "set target",
+			target:    fptr(4),
+			completed: repeatResult(4, win),
+			remaining: 6,
+			want: outcome{target: 4, magic: [2]*float64{fptr(0), fptr(4)},
+				clinched: true, winnerTeamID: sptr("t1")},
+		},
+		{
+			name:      "level on a low target is shared",
+			target:    fptr(3),
+			completed: append(repeatResult(3, win), repeatResult(3, loss)...),
+			remaining: 4,
+			want: outcome{target: 3, magic: [2]*float64{fptr(0), fptr(0)},
+				clinched: true, shared: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tournament := &models.Tournament{ID: "cup", TargetPoints: tt.target, DefendingTeamID: tt.defending}
+			teams := []models.Team{{ID: "t1", Name: "Team 1"}, {ID: "t2", Name: "Team 2"}}
+
+			status := cupStatus(tournament, teams, cupMatches(tt.completed, tt.remaining))
+
+			got := outcome{
+				target:       status.TargetPoints,
+				clinched:     status.Clinched,
+				winnerTeamID: status.WinnerTeamID,
+				retained:     status.Retained,
+				shared:       status.Shared,
+			}
+			for i, team := range status.Teams {
+				got.magic[i], got.retain[i], got.eliminated[i] = team.MagicNumber, team.RetainNumber, team.Eliminated
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cupStatus = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// String prints an outcome with its pointers followed, for failure messages
+func (o outcome) String() string {
+	return fmt.Sprintf("{target:%v magic:%s retain:%s eliminated:%v clinched:%v winner:%s retained:%v shared:%v}",
+		o.target, optionalFloats(o.magic), optionalFloats(o.retain), o.eliminated, o.clinched, optionalString(o.winnerTeamID), o.retained, o.shared)
+}
+
+func optionalFloats(values [2]*float64) string {
+	s := make([]string, len(values))
+	for i, value := range values {
+		s[i] = "nil"
+		if value != nil {
+			s[i] = fmt.Sprint(*value)
+		}
+	}
+	return "[" + strings.Join(s, " ") + "]"
+}
+
+func optionalString(value *string) string {
+	if value == nil {
+		return "nil"
+	}
+	return *value
+}
@@ -0,0 +1,201 @@
+package scoring
+
+import (
+	"fmt"
+
+	"mayhamapi/models"
+)
+
+// applyConcessions records the match's hole concessions and writes each conceded putt
+// into the hole's scores, replacing whatever the player had submitted
+func (ctx *matchContext) applyConcessions(concessions []models.Concession, holeScores map[int][]models.Score) {
+	ctx.concededHoles = make(map[int]string)
+	ctx.holeConcessions = make(map[int][]models.Concession)
+
+	for _, concession := range concessions {
+		if concession.HoleNumber == nil {
+			continue
+		}
+		holeNum := *concession.HoleNumber
+		ctx.holeConcessions[holeNum] = append(ctx.holeConcessions[holeNum], concession)
+
+		switch concession.Type {
+		case models.ConcedeHole:
+			// Total formats are decided on strokes, so a conceded hole can't stand in for a score
+			if _, ok := ctx.scorer.(totalScorer); !ok {
+				ctx.concededHoles[holeNum] = concession.ConcedingTeamID
+			}
+		case models.ConcedePutt:
+			if concession.UserID == nil || concession.Strokes == nil {
+				continue
+			}
+			conceded := models.Score{
+				MatchID:    ctx.match.ID,
+				UserID:     *concession.UserID,
+				HoleNumber: holeNum,
+				Strokes:    *concession.Strokes,
+			}
+			replaced := false
+			for i, score := range holeScores[holeNum] {
+				if score.UserID == conceded.UserID {
+					conceded.ID = score.ID
+					holeScores[holeNum][i] = conceded
+					replaced = true
+				}
+			}
+			if !replaced {
+				holeScores[holeNum] = append(holeScores[holeNum], conceded)
+			}
+		}
+	}
+}
+
+// holeDecided reports whether a hole has a result: either every score needed is in, or it was conceded
+func (ctx *matchContext) holeDecided(hole *HoleInput) bool {
+	return ctx.concededHoles[hole.HoleNumber] != "" || ctx.roster.holeComplete(ctx.scorer, hole)
+}
+
+// scoreHole scores a hole with the match's format. A conceded hole goes to the other
+// side, taking every contest on it, whatever strokes were posted.
+func (ctx *matchContext) scoreHole(hole *HoleInput) (*HoleResult, error) {
+	concededBy := ctx.concededHoles[hole.HoleNumber]
+	if concededBy == "" {
+		result, err := ctx.scorer.ScoreHole(hole)
+		if err != nil {
+			return nil, err
+		}
+		result.Concessions = ctx.holeConcessions[hole.HoleNumber]
+		return result, nil
+	}
+
+	result := &HoleResult{HoleNumber: hole.HoleNumber, Par: hole.Par, PlayerScores: hole.playerScores()}
+	if ctx.roster.holeComplete(ctx.scorer, hole) {
+		scored, err := ctx.scorer.ScoreHole(hole)
+		if err != nil {
+			return nil, err
+		}
+		result = scored
+	}
+
+	winner := ctx.match.Team1ID
+	if concededBy == ctx.match.Team1ID {
+		winner = ctx.match.Team2ID
+	}
+	result.WinnerTeamID = &winner
+	result.ConcededByTeamID = &concededBy
+	result.Concessions = ctx.holeConcessions[hole.HoleNumber]
+
+	points := float64(ctx.swing())
+	result.Team1Points, result.Team2Points = 0, 0
+	if winner == ctx.match.Team1ID {
+		result.Team1Points = points
+	} else {
+		result.Team2Points = points
+	}
+	// Each contest keeps its own value (both sides' points, as a tie splits them) so the
+	// contests still add up to the hole
+	for i := range result.Contests {
+		contest := &result.Contests[i]
+		value := contest.Team1Points + contest.Team2Points
+		contest.WinnerTeamID = &winner
+		contest.Team1Points, contest.Team2Points = 0, 0
+		if winner == ctx.match.Team1ID {
+			contest.Team1Points = value
+		} else {
+			contest.Team2Points = value
+		}
+	}
+
+	return result, nil
+}
+
+// concede ends the match in favour of the side that didn't concede. Match play results
+// keep the standard notation when the winner was already ahead, e.g. "3&5".
+func concede(match *models.Match, status *MatchStatus, concedingTeamID string) {
+	winner, margin := match.Team1ID, status.Team1Up
+	if concedingTeamID == match.Team1ID {
+		winner, margin = match.Team2ID, -status.Team1Up
+	}
+
+	status.MatchComplete = true
+	status.Dormie = false
+	status.WinnerTeamID = &winner
+	status.LeaderTeamID = &winner
+	status.ConcededByTeamID = &concedingTeamID
+
+	if status.ScoringUnit == "holes" && margin > 0 && status.HolesRemaining > 0 && !status.InPlayoff {
+		status.ResultText = fmt.Sprintf("%d&%d", margin, status.HolesRemaining)
+	} else {
+		status.ResultText = "Conceded"
+	}
+}
+
+// NewConcession validates a concession request against the match's current state and
+// returns the concession to record. Putt concessions are charged to the player's opponents.
+func (s *ScoringService) NewConcession(match *models.Match, req *models.CreateConcessionRequest) (*models.Concession, error) {
+	ctx, err := s.loadMatchContext(match)
+	if err != nil {
+		return nil, err
+	}
+	scores, err := s.repo.GetMatchScores(match.ID)
+	if err != nil {
+		return nil, err
+	}
+	status, err := s.CalculateMatchStatus(match, scores)
+	if err != nil {
+		return nil, err
+	}
+	if status.MatchComplete {
+		return nil, fmt.Errorf("match is already complete")
+	}
+
+	concession := &models.Concession{MatchID: match.ID, Type: req.Type, ConcedingTeamID: req.TeamID}
+
+	if req.Type == models.ConcedePutt {
+		if req.UserID == nil || req.Strokes == nil {
+			return nil, fmt.Errorf("putt concessions need the player's user_id and strokes for the hole")
+		}
+		switch ctx.roster.side(*req.UserID) {
+		case 1:
+			concession.ConcedingTeamID = match.Team2ID
+		case 2:
+			concession.ConcedingTeamID = match.Team1ID
+		default:
+			return nil, fmt.Errorf("player %s is not playing in this match", *req.UserID)
+		}
+		concession.UserID = req.UserID
+		concession.Strokes = req.Strokes
+	} else if req.TeamID != match.Team1ID && req.TeamID != match.Team2ID {
+		return nil, fmt.Errorf("team_id must be one of the teams in this match")
+	}
+
+	if req.Type == models.ConcedeMatch {
+		return concession, nil
+	}
+
+	if req.HoleNumber == nil {
+		return nil, fmt.Errorf("hole_number is required for %s concessions", req.Type)
+	}
+	holeNum := *req.HoleNumber
+	inMatch := holeNum >= ctx.firstPlayoffHole() && status.InPlayoff
+	for _, h := range ctx.holes {
+		inMatch = inMatch || h == holeNum
+	}
+	if !inMatch {
+		return nil, fmt.Errorf("hole %d is not part of this match", holeNum)
+	}
+	concession.HoleNumber = &holeNum
+
+	if req.Type == models.ConcedeHole {
+		if _, ok := ctx.scorer.(totalScorer); ok {
+			return nil, fmt.Errorf("holes can't be conceded in stroke play or Stableford formats")
+		}
+		for _, existing := range status.Concessions {
+			if existing.Type == models.ConcedeHole && existing.HoleNumber != nil && *existing.HoleNumber == holeNum {
+				return nil, fmt.Errorf("hole %d has already been conceded", holeNum)
+			}
+		}
+	}
+
+	return concession, nil
+}
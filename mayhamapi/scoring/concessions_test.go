@@ -0,0 +1,243 @@
+package scoring
+
+import (
+	"reflect"
+	"testing"
+
+	"mayhamapi/models"
+)
+
+// singlesContext is the scoring context for a gross singles match between a1 (t1) and b1 (t2)
+func singlesContext(holes int, scorer FormatScorer) *matchContext {
+	match := testMatch(holes)
+	return &matchContext{
+		match:  match,
+		scorer: scorer,
+		roster: &MatchRoster{Team1: []string{"a1"}, Team2: []string{"b1"}},
+		holes:  match.HolesOn(18),
+	}
+}
+
+func TestMatchStatusAppliesNewConcession(t *testing.T) {
+	hole3 := 3
+	tests := []struct {
+		name       string
+		concession models.Concession
+		complete   bool
+		winner     string
+		resultText string
+	}{
+		{
+			name:       "match conceded",
+			concession: models.Concession{Type: models.ConcedeMatch, ConcedingTeamID: "t2"},
+			complete:   true, winner: "t1", resultText: "1&1",
+		},
+		{
+			name:       "last hole conceded",
+			concession: models.Concession{Type: models.ConcedeHole, HoleNumber: &hole3, ConcedingTeamID: "t1"},
+			complete:   true, resultText: "Halved",
+		},
+	}
+
+	// Team 1 won the first hole and halved the second, with the third still to play
+	posted := singles([2]int{3, 4}, [2]int{4, 4})
+	scores := append(posted[1], posted[2]...)
+
+	before, err := singlesContext(3, matchPlayScorer{}).matchStatus(scores, nil, nil)
+	if err != nil {
+		t.Fatalf("matchStatus: %v", err)
+	}
+	if before.MatchComplete || before.ResultText != "1 UP" {
+		t.Fatalf("before the concession: complete %v, %q; want in progress at 1 UP", before.MatchComplete, before.ResultText)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := singlesContext(3, matchPlayScorer{}).matchStatus(scores, []models.Concession{tt.concession}, nil)
+			if err != nil {
+				t.Fatalf("matchStatus: %v", err)
+			}
+
+			winner := ""
+			if status.WinnerTeamID != nil {
+				winner = *status.WinnerTeamID
+			}
+			if status.MatchComplete != tt.complete || winner != tt.winner || status.ResultText != tt.resultText {
+				t.Errorf("complete, winner, result = %v, %q, %q, want %v, %q, %q",
+					status.MatchComplete, winner, status.ResultText, tt.complete, tt.winner, tt.resultText)
+			}
+			if len(status.Concessions) != 1 {
+				t.Errorf("status lists %d concessions, want the new one", len(status.Concessions))
+			}
+		})
+	}
+}
+
+func TestApplyConcessions(t *testing.T) {
+	hole1, hole2 := 1, 2
+	a1, b1 := "a1", "b1"
+	three, four := 3, 4
+
+	tests := []struct {
+		name        string
+		scorer      FormatScorer
+		concessions []models.Concession
+		strokes     map[int]map[string]int // strokes counted after the concessions, by hole then user ID
+		conceded    map[int]string
+	}{
+		{
+			name:        "conceded putt replaces the submitted score",
+			scorer:      matchPlayScorer{},
+			concessions: []models.Concession{{Type: models.ConcedePutt, HoleNumber: &hole1, UserID: &a1, Strokes: &four, ConcedingTeamID: "t2"}},
+			strokes:     map[int]map[string]int{1: {"a1": 4, "b1": 5}},
+			conceded:    map[int]string{},
+		},
+		{
+			name:        "conceded putt stands in for a missing score",
+			scorer:      matchPlayScorer{},
+			concessions: []models.Concession{{Type: models.ConcedePutt, HoleNumber: &hole2, UserID: &b1, Strokes: &three, ConcedingTeamID: "t1"}},
+			strokes:     map[int]map[string]int{1: {"a1": 6, "b1": 5}, 2: {"b1": 3}},
+			conceded:    map[int]string{},
+		},
+		{
+			name:        "putt concession without strokes is ignored",
+			scorer:      matchPlayScorer{},
+			concessions: []models.Concession{{Type: models.ConcedePutt, HoleNumber: &hole1, UserID: &a1, ConcedingTeamID: "t2"}},
+			strokes:     map[int]map[string]int{1: {"a1": 6, "b1": 5}},
+			conceded:    map[int]string{},
+		},
+		{
+			name:        "conceded hole",
+			scorer:      matchPlayScorer{},
+			concessions: []models.Concession{{Type: models.ConcedeHole, HoleNumber: &hole2, ConcedingTeamID: "t1"}},
+			strokes:     map[int]map[string]int{1: {"a1": 6, "b1": 5}},
+			conceded:    map[int]string{2: "t1"},
+		},
+		{
+			name:        "total formats can't concede a hole",
+			scorer:      strokePlayScorer{},
+			concessions: []models.Concession{{Type: models.ConcedeHole, HoleNumber: &hole2, ConcedingTeamID: "t1"}},
+			strokes:     map[int]map[string]int{1: {"a1": 6, "b1": 5}},
+			conceded:    map[int]string{},
+		},
+		{
+			name:        "match concessions aren't tied to a hole",
+			scorer:      matchPlayScorer{},
+			concessions: []models.Concession{{Type: models.ConcedeMatch, ConcedingTeamID: "t2"}},
+			strokes:     map[int]map[string]int{1: {"a1": 6, "b1": 5}},
+			conceded:    map[int]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := singlesContext(2, tt.scorer)
+			holeScores := singles([2]int{6, 5})
+			ctx.applyConcessions(tt.concessions, holeScores)
+
+			strokes := make(map[int]map[string]int)
+			for holeNum, scores := range holeScores {
+				strokes[holeNum] = make(map[string]int)
+				for _, score := range scores {
+					strokes[holeNum][score.UserID] = score.Counted()
+				}
+			}
+			if !reflect.DeepEqual(strokes, tt.strokes) {
+				t.Errorf("strokes = %v, want %v", strokes, tt.strokes)
+			}
+			if !reflect.DeepEqual(ctx.concededHoles, tt.conceded) {
+				t.Errorf("conceded holes = %v, want %v", ctx.concededHoles, tt.conceded)
+			}
+			for _, concession := range tt.concessions {
+				if concession.HoleNumber != nil && len(ctx.holeConcessions[*concession.HoleNumber]) != 1 {
+					t.Errorf("hole %d lists %d concessions, want 1", *concession.HoleNumber, len(ctx.holeConcessions[*concession.HoleNumber]))
+				}
+			}
+		})
+	}
+}
+
+func TestScoreConcededHole(t *testing.T) {
+	tests := []struct {
+		name                     string
+		scorer                   FormatScorer
+		roster                   *MatchRoster
+		team1, team2             map[string]int
+		team1Points, team2Points float64
+		scored                   bool
+	}{
+		{
+			name: "goes to the other side whatever was posted", scorer: matchPlayScorer{},
+			roster: &MatchRoster{Team1: []string{"a1"}, Team2: []string{"b1"}},
+			team1:  map[string]int{"a1": 3}, team2: map[string]int{"b1": 6},
+			team2Points: 1, scored: true,
+		},
+		{
+			name: "before any scores are posted", scorer: matchPlayScorer{},
+			roster:      &MatchRoster{Team1: []string{"a1"}, Team2: []string{"b1"}},
+			team2Points: 1,
+		},
+		{
+			name: "takes every split high-low contest", scorer: highLowScorer{split: true},
+			roster: &MatchRoster{Team1: []string{"a1", "a2"}, Team2: []string{"b1", "b2"}},
+			team1:  map[string]int{"a1": 3, "a2": 4}, team2: map[string]int{"b1": 5, "b2": 6},
+			team2Points: 2, scored: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := singlesContext(18, tt.scorer)
+			ctx.roster = tt.roster
+			ctx.concededHoles = map[int]string{1: "t1"}
+			hole := &HoleInput{Match: ctx.match, HoleNumber: 1, Team1: postScores(1, tt.team1), Team2: postScores(1, tt.team2)}
+
+			result, err := ctx.scoreHole(hole)
+			if err != nil {
+				t.Fatalf("scoreHole: %v", err)
+			}
+			if result.WinnerTeamID == nil || *result.WinnerTeamID != "t2" || result.ConcededByTeamID == nil || *result.ConcededByTeamID != "t1" {
+				t.Errorf("winner %v conceded by %v, want t2 conceded by t1", optionalString(result.WinnerTeamID), optionalString(result.ConcededByTeamID))
+			}
+			if result.Team1Points != tt.team1Points || result.Team2Points != tt.team2Points {
+				t.Errorf("points = %v-%v, want %v-%v", result.Team1Points, result.Team2Points, tt.team1Points, tt.team2Points)
+			}
+			// The strokes posted are still shown when everyone had holed out
+			if scored := result.Team1Score != nil; scored != tt.scored {
+				t.Errorf("hole has team scores %v, want %v", scored, tt.scored)
+			}
+		})
+	}
+}
+
+func TestConcede(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     MatchStatus
+		conceding  string
+		winner     string
+		resultText string
+	}{
+		{"trailing side concedes", MatchStatus{ScoringUnit: "holes", Team1Up: 3, HolesRemaining: 5}, "t2", "t1", "3&5"},
+		{"team 1 trailing concedes", MatchStatus{ScoringUnit: "holes", Team1Up: -2, HolesRemaining: 4}, "t1", "t2", "2&4"},
+		{"leading side concedes", MatchStatus{ScoringUnit: "holes", Team1Up: 2, HolesRemaining: 4}, "t1", "t2", "Conceded"},
+		{"level match", MatchStatus{ScoringUnit: "holes", HolesRemaining: 6}, "t2", "t1", "Conceded"},
+		{"conceded in a playoff", MatchStatus{ScoringUnit: "holes", InPlayoff: true}, "t2", "t1", "Conceded"},
+		{"stroke play", MatchStatus{ScoringUnit: "strokes", Team1Up: 3, HolesRemaining: 5}, "t2", "t1", "Conceded"},
+		{"dormie cleared", MatchStatus{ScoringUnit: "holes", Team1Up: 2, HolesRemaining: 2, Dormie: true}, "t2", "t1", "2&2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := tt.status
+			concede(testMatch(18), &status, tt.conceding)
+
+			if !status.MatchComplete || status.Dormie || optionalString(status.WinnerTeamID) != tt.winner ||
+				optionalString(status.ConcededByTeamID) != tt.conceding || status.ResultText != tt.resultText {
+				t.Errorf("complete %v dormie %v winner %s conceded by %s %q, want complete, won by %s conceded by %s %q",
+					status.MatchComplete, status.Dormie, optionalString(status.WinnerTeamID), optionalString(status.ConcededByTeamID),
+					status.ResultText, tt.winner, tt.conceding, tt.resultText)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package scoring
+
+import (
+	"fmt"
+
+	"mayhamapi/models"
+)
+
+// matchContext gathers everything loaded from the database that's needed to score one match
+type matchContext struct {
+	match      *models.Match
+	format     models.MatchFormat
+	config     models.FormatConfig
+	scorer     FormatScorer
+	roster     *MatchRoster
+	holes      []int                  // hole numbers in the order they're played
+	course     map[int]models.Hole    // the round tee's scorecard by hole number; empty without course data
+	tees       map[string]*models.Tee // tee played by each user ID; nil entries without course data
+	playing    map[string]int         // playing handicaps by user ID; nil for gross matches
+	allocation StrokeAllocation
+	// concededHoles maps a conceded hole number to the conceding team ID; holeConcessions
+	// holds the hole and putt concessions recorded on each hole
+	concededHoles   map[int]string
+	holeConcessions map[int][]models.Concession
+}
+
+func (s *ScoringService) loadMatchContext(match *models.Match) (*matchContext, error) {
+	definition, scorer, err := s.scorerForMatch(match)
+	if err != nil {
+		return nil, err
+	}
+
+	roster, err := s.loadRoster(match)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := &matchContext{
+		match:  match,
+		format: definition.ScoringType,
+		config: definition.Config,
+		scorer: scorer,
+		roster: roster,
+		course: make(map[int]models.Hole),
+	}
+
+	round, err := s.repo.GetRound(match.RoundID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load round: %w", err)
+	}
+
+	// Load every tee in use so course handicaps can account for rating and slope
+	tees := make(map[string]*models.Tee)
+	var roundTee *models.Tee
+	if round.TeeID != nil {
+		if roundTee, err = s.repo.GetTee(*round.TeeID); err != nil {
+			return nil, fmt.Errorf("failed to load round tee: %w", err)
+		}
+		tees[roundTee.ID] = roundTee
+		for _, hole := range roundTee.Holes {
+			ctx.course[hole.HoleNumber] = hole
+		}
+		if len(ctx.course) == 0 {
+			return nil, fmt.Errorf("round tee has no holes")
+		}
+	}
+	ctx.holes = match.HolesOn(ctx.courseHoles())
+	playerTees := make(map[string]*models.Tee)
+	for _, userID := range roster.players() {
+		playerTees[userID] = roundTee
+		teeID, ok := roster.Tees[userID]
+		if !ok {
+			continue
+		}
+		if _, loaded := tees[teeID]; !loaded {
+			if tees[teeID], err = s.repo.GetTee(teeID); err != nil {
+				return nil, fmt.Errorf("failed to load player tee: %w", err)
+			}
+		}
+		playerTees[userID] = tees[teeID]
+	}
+	ctx.tees = playerTees
+
+	if match.HandicapMode != "" && match.HandicapMode != models.HandicapGross {
+		courseHandicaps := make(map[string]float64)
+		for _, userID := range roster.players() {
+			courseHandicaps[userID] = courseHandicap(roster.Handicaps[userID], playerTees[userID])
+		}
+		ctx.playing = playingHandicaps(match, ctx.format, scorer, roster, courseHandicaps)
+	}
+	ctx.allocation = allocateStrokes(match, ctx.playing, ctx.holes, ctx.strokeIndexes())
+
+	return ctx, nil
+}
+
+// courseHoles is the number of holes on the round's course: its highest hole number, or
+// models.DefaultCourseHoles without course data
+func (ctx *matchContext) courseHoles() int {
+	if len(ctx.course) == 0 {
+		return models.DefaultCourseHoles
+	}
+	last := 0
+	for holeNum := range ctx.course {
+		if holeNum > last {
+			last = holeNum
+		}
+	}
+	return last
+}
+
+// strokeIndexes returns the stroke index of each hole in play, falling back to
+// hole order when the round has no course data
+func (ctx *matchContext) strokeIndexes() map[int]int {
+	if len(ctx.course) == 0 {
+		return defaultStrokeIndexes(ctx.holes)
+	}
+
+	strokeIndex := make(map[int]int, len(ctx.holes))
+	for _, holeNum := range ctx.holes {
+		if hole, ok := ctx.course[holeNum]; ok {
+			strokeIndex[holeNum] = hole.StrokeIndex
+		} else {
+			strokeIndex[holeNum] = holeNum + 18 // unknown holes rank after every rated hole
+		}
+	}
+	return strokeIndex
+}
+
+// par returns the par for a hole, or nil when the round has no course data for it
+func (ctx *matchContext) par(holeNumber int) *int {
+	if hole, ok := ctx.course[holeNumber]; ok {
+		return &hole.Par
+	}
+	return nil
+}
+
+// swing is the most a match play margin can move on one hole: 1 hole, or one point per contest
+func (ctx *matchContext) swing() int {
+	if contests, ok := ctx.scorer.(contestScorer); ok {
+		return contests.contestsPerHole()
+	}
+	return 1
+}
@@ -0,0 +1,37 @@
+package scoring
+
+import (
+	"testing"
+
+	"mayhamapi/models"
+)
+
+func TestCourseHoles(t *testing.T) {
+	course := func(holes ...int) map[int]models.Hole {
+		scorecard := make(map[int]models.Hole)
+		for _, holeNum := range holes {
+			scorecard[holeNum] = models.Hole{HoleNumber: holeNum, Par: 4}
+		}
+		return scorecard
+	}
+
+	tests := []struct {
+		name   string
+		course map[int]models.Hole
+		want   int
+	}{
+		{"no course data", nil, models.DefaultCourseHoles},
+		{"nine-hole course", course(1, 2, 3, 4, 5, 6, 7, 8, 9), 9},
+		{"eighteen-hole course", course(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18), 18},
+		{"twenty-seven-hole course", course(1, 9, 18, 27), 27},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &matchContext{course: tt.course}
+			if got := ctx.courseHoles(); got != tt.want {
+				t.Errorf("courseHoles = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
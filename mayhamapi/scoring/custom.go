@@ -0,0 +1,145 @@
+package scoring
+
+import (
+	"fmt"
+	"sort"
+
+	"mayhamapi/models"
+)
+
+// customScorer interprets a format defined in FormatConfig.Contests. Every contest combines
+// each side's scores on the hole its own way, and the lower team score takes its points.
+type customScorer struct {
+	contests []models.ContestRule
+	gross    bool
+}
+
+func newCustomScorer(config models.FormatConfig) customScorer {
+	contests := make([]models.ContestRule, len(config.Contests))
+	for i, rule := range config.Contests {
+		if rule.Points == 0 {
+			rule.Points = 1
+		}
+		if rule.Name == "" {
+			rule.Name = rule.Combine
+		}
+		contests[i] = rule
+	}
+	return customScorer{contests: contests, gross: config.Gross}
+}
+
+func (sc customScorer) contestsPerHole() int {
+	points := 0
+	for _, rule := range sc.contests {
+		points += rule.Points
+	}
+	return points
+}
+
+func (sc customScorer) ScoreHole(hole *HoleInput) (*HoleResult, error) {
+	team1, team2 := sc.sortedScores(hole, hole.Team1), sc.sortedScores(hole, hole.Team2)
+
+	contests := make([]models.HoleContest, 0, len(sc.contests))
+	for _, rule := range sc.contests {
+		team1Score, err := combineScores(rule, team1)
+		if err != nil {
+			return nil, err
+		}
+		team2Score, err := combineScores(rule, team2)
+		if err != nil {
+			return nil, err
+		}
+
+		contest := newContest(hole, rule.Name, team1Score, team2Score)
+		contest.Team1Points *= float64(rule.Points)
+		contest.Team2Points *= float64(rule.Points)
+		contests = append(contests, contest)
+	}
+
+	return contestHole(hole, contests...), nil
+}
+
+// sortedScores returns a side's (net, unless the format is gross) scores, lowest first
+func (sc customScorer) sortedScores(hole *HoleInput, scores []models.Score) []int {
+	values := make([]int, 0, len(scores))
+	for _, score := range scores {
+		if sc.gross {
+			values = append(values, score.Counted())
+		} else {
+			values = append(values, hole.net(score))
+		}
+	}
+	sort.Ints(values)
+	return values
+}
+
+// combineScores reduces a side's sorted scores to its team score for one contest
+func combineScores(rule models.ContestRule, scores []int) (int, error) {
+	need := 1
+	if rule.Combine == models.CombineNthBest || rule.Combine == models.CombineBestN {
+		need = rule.N
+	}
+	if len(scores) < need {
+		return 0, fmt.Errorf("contest %q needs at least %d scores per side", rule.Name, need)
+	}
+
+	switch rule.Combine {
+	case models.CombineMin:
+		return scores[0], nil
+	case models.CombineMax:
+		return scores[len(scores)-1], nil
+	case models.CombineNthBest:
+		return scores[rule.N-1], nil
+	case models.CombineSum, models.CombineBestN:
+		count := len(scores)
+		if rule.Combine == models.CombineBestN {
+			count = rule.N
+		}
+		total := 0
+		for _, score := range scores[:count] {
+			total += score
+		}
+		return total, nil
+	default:
+		return 0, fmt.Errorf("unknown combine %q for contest %q", rule.Combine, rule.Name)
+	}
+}
+
+// ValidateFormat checks a new format definition can be scored: a known scoring type, and
+// for custom formats a set of contests that the format's sides can fill
+func ValidateFormat(format *models.MatchFormatDefinition) error {
+	if _, err := newFormatScorer(format); err != nil {
+		return err
+	}
+
+	if format.ScoringType != models.Custom {
+		if len(format.Config.Contests) > 0 {
+			return fmt.Errorf("contests can only be set on custom formats")
+		}
+		return nil
+	}
+
+	if len(format.Config.Contests) == 0 {
+		return fmt.Errorf("a custom format needs at least one contest")
+	}
+	names := make(map[string]bool)
+	for i, rule := range newCustomScorer(format.Config).contests {
+		switch rule.Combine {
+		case models.CombineMin, models.CombineMax, models.CombineSum:
+		case models.CombineNthBest, models.CombineBestN:
+			if rule.N < 1 || rule.N > format.PlayersPerSide {
+				return fmt.Errorf("contest %d: n must be between 1 and players_per_side (%d)", i+1, format.PlayersPerSide)
+			}
+		default:
+			return fmt.Errorf("contest %d: combine must be one of min, max, sum, nth_best or best_n", i+1)
+		}
+		if rule.Points < 1 {
+			return fmt.Errorf("contest %d: points must be a positive whole number", i+1)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("contest name %q is used more than once", rule.Name)
+		}
+		names[rule.Name] = true
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package scoring
+
+import (
+	"reflect"
+	"testing"
+
+	"mayhamapi/models"
+)
+
+func TestCombineScores(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    models.ContestRule
+		scores  []int // sorted, lowest first
+		want    int
+		wantErr bool
+	}{
+		{"min", models.ContestRule{Combine: models.CombineMin}, []int{3, 4, 6}, 3, false},
+		{"max", models.ContestRule{Combine: models.CombineMax}, []int{3, 4, 6}, 6, false},
+		{"sum", models.ContestRule{Combine: models.CombineSum}, []int{3, 4, 6}, 13, false},
+		{"second best", models.ContestRule{Combine: models.CombineNthBest, N: 2}, []int{3, 4, 6}, 4, false},
+		{"best two", models.ContestRule{Combine: models.CombineBestN, N: 2}, []int{3, 4, 6}, 7, false},
+		{"best n of exactly n", models.ContestRule{Combine: models.CombineBestN, N: 3}, []int{3, 4, 6}, 13, false},
+		{"single score", models.ContestRule{Combine: models.CombineMax}, []int{5}, 5, false},
+		{"nth best needs n scores", models.ContestRule{Combine: models.CombineNthBest, N: 3}, []int{3, 4}, 0, true},
+		{"best n needs n scores", models.ContestRule{Combine: models.CombineBestN, N: 3}, []int{3, 4}, 0, true},
+		{"no scores", models.ContestRule{Combine: models.CombineMin}, nil, 0, true},
+		{"unknown combine", models.ContestRule{Combine: "median"}, []int{3, 4}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := combineScores(tt.rule, tt.scores)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("combineScores error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("combineScores = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCustomScoreHole(t *testing.T) {
+	lowAndTotal := models.FormatConfig{Contests: []models.ContestRule{
+		{Name: "low", Combine: models.CombineMin, Points: 2},
+		{Name: "total", Combine: models.CombineSum},
+	}}
+	grossLowAndTotal := lowAndTotal
+	grossLowAndTotal.Gross = true
+
+	type contest struct {
+		name                     string
+		team1Points, team2Points float64
+	}
+	tests := []struct {
+		name         string
+		config       models.FormatConfig
+		team1, team2 map[string]int
+		strokes      map[string]int
+		contests     []contest
+		winner       string
+	}{
+		{
+			name: "net scores, points per contest", config: lowAndTotal,
+			team1: map[string]int{"a1": 4, "a2": 6}, team2: map[string]int{"b1": 5, "b2": 5},
+			strokes:  map[string]int{"a2": 1},
+			contests: []contest{{"low", 2, 0}, {"total", 1, 0}},
+			winner:   "t1",
+		},
+		{
+			name: "gross ignores strokes", config: grossLowAndTotal,
+			team1: map[string]int{"a1": 4, "a2": 6}, team2: map[string]int{"b1": 5, "b2": 5},
+			strokes:  map[string]int{"a2": 1},
+			contests: []contest{{"low", 2, 0}, {"total", 0.5, 0.5}},
+			winner:   "t1",
+		},
+		{
+			name: "a tied contest splits its points", config: lowAndTotal,
+			team1: map[string]int{"a1": 4, "a2": 7}, team2: map[string]int{"b1": 4, "b2": 6},
+			contests: []contest{{"low", 1, 1}, {"total", 0, 1}},
+			winner:   "t2",
+		},
+		{
+			name: "contests won by different sides", config: lowAndTotal,
+			team1: map[string]int{"a1": 3, "a2": 7}, team2: map[string]int{"b1": 4, "b2": 5},
+			contests: []contest{{"low", 2, 0}, {"total", 0, 1}},
+			winner:   "t1",
+		},
+		{
+			name: "nth best with a default name", config: models.FormatConfig{Contests: []models.ContestRule{{Combine: models.CombineNthBest, N: 2}}},
+			team1: map[string]int{"a1": 3, "a2": 6}, team2: map[string]int{"b1": 5, "b2": 5},
+			contests: []contest{{models.CombineNthBest, 0, 1}},
+			winner:   "t2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hole := &HoleInput{Match: testMatch(18), HoleNumber: 1, Team1: postScores(1, tt.team1), Team2: postScores(1, tt.team2), Strokes: tt.strokes}
+			result, err := newCustomScorer(tt.config).ScoreHole(hole)
+			if err != nil {
+				t.Fatalf("ScoreHole: %v", err)
+			}
+
+			var got []contest
+			for _, c := range result.Contests {
+				got = append(got, contest{c.Name, c.Team1Points, c.Team2Points})
+			}
+			if !reflect.DeepEqual(got, tt.contests) {
+				t.Errorf("contests = %+v, want %+v", got, tt.contests)
+			}
+			winner := ""
+			if result.WinnerTeamID != nil {
+				winner = *result.WinnerTeamID
+			}
+			if winner != tt.winner {
+				t.Errorf("winner = %q, want %q", winner, tt.winner)
+			}
+		})
+	}
+}
+
+func TestCustomScoreHoleShortSide(t *testing.T) {
+	scorer := newCustomScorer(models.FormatConfig{Contests: []models.ContestRule{{Combine: models.CombineBestN, N: 2}}})
+	hole := &HoleInput{Match: testMatch(18), HoleNumber: 1, Team1: postScores(1, map[string]int{"a1": 4}), Team2: postScores(1, map[string]int{"b1": 4, "b2": 5})}
+	if _, err := scorer.ScoreHole(hole); err == nil {
+		t.Error("ScoreHole succeeded with one score for best 2, want an error")
+	}
+}
+
+func TestCustomConcededHole(t *testing.T) {
+	match := testMatch(18)
+	scorer := newCustomScorer(models.FormatConfig{Contests: []models.ContestRule{
+		{Name: "low", Combine: models.CombineMin, Points: 2},
+		{Name: "total", Combine: models.CombineSum},
+	}})
+	ctx := &matchContext{
+		match:         match,
+		scorer:        scorer,
+		roster:        &MatchRoster{Team1: []string{"a1", "a2"}, Team2: []string{"b1", "b2"}},
+		holes:         match.HolesOn(18),
+		concededHoles: map[int]string{1: "t1"},
+	}
+	// Team 1 would have won both contests on the strokes posted
+	hole := &HoleInput{Match: match, HoleNumber: 1,
+		Team1: postScores(1, map[string]int{"a1": 3, "a2": 4}), Team2: postScores(1, map[string]int{"b1": 5, "b2": 5})}
+
+	result, err := ctx.scoreHole(hole)
+	if err != nil {
+		t.Fatalf("scoreHole: %v", err)
+	}
+	if result.Team1Points != 0 || result.Team2Points != 3 {
+		t.Errorf("points = %v-%v, want 0-3", result.Team1Points, result.Team2Points)
+	}
+	for _, c := range result.Contests {
+		want := map[string]float64{"low": 2, "total": 1}[c.Name]
+		if c.WinnerTeamID == nil || *c.WinnerTeamID != "t2" || c.Team2Points != want || c.Team1Points != 0 {
+			t.Errorf("contest %s = %v-%v, want 0-%v to t2", c.Name, c.Team1Points, c.Team2Points, want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package scoring
+
+import (
+	"math"
+	"sort"
+
+	"mayhamapi/models"
+)
+
+// defaultAllowances are the handicap allowance percentages used when a match doesn't set its own.
+// Team-ball formats apply the allowance to the side's combined handicap.
+var defaultAllowances = map[models.MatchFormat]float64{
+	models.MatchPlay:     100,
+	models.BestBall:      90,
+	models.Shamble:       85,
+	models.HighLow:       100,
+	models.AlternateShot: 50,
+	models.Scramble:      25,
+	models.StrokePlay:    95,
+	models.Stableford:    95,
+	models.Nassau:        100,
+}
+
+// StrokeAllocation holds the handicap strokes each player receives, by hole number then user ID
+type StrokeAllocation map[int]map[string]int
+
+func (a StrokeAllocation) forHole(holeNumber int) map[string]int {
+	if a == nil {
+		return nil
+	}
+	return a[holeNumber]
+}
+
+// courseHandicap converts a handicap index into a course handicap for the tee being played.
+// Without a rated tee the index is used as-is.
+func courseHandicap(index float64, tee *models.Tee) float64 {
+	if tee == nil || tee.Slope == nil || tee.CourseRating == nil || len(tee.Holes) == 0 {
+		return index
+	}
+	return index*float64(*tee.Slope)/113 + (*tee.CourseRating - float64(tee.Par()))
+}
+
+// playingHandicaps applies the match's allowance to each player's course handicap.
+// Team-ball formats get one playing handicap per side, assigned to every member of that side.
+func playingHandicaps(match *models.Match, format models.MatchFormat, scorer FormatScorer, roster *MatchRoster, courseHandicaps map[string]float64) map[string]int {
+	allowance, ok := defaultAllowances[format]
+	if !ok {
+		allowance = 100
+	}
+	if match.HandicapAllowance != nil {
+		allowance = *match.HandicapAllowance
+	}
+
+	// Handicap indexes are 18-hole figures, so shorter matches get a proportional share
+	scale := allowance / 100 * float64(match.Holes) / 18
+
+	playing := make(map[string]int)
+	if _, ok := scorer.(teamBallScorer); ok {
+		for _, team := range [][]string{roster.Team1, roster.Team2} {
+			combined := 0.0
+			for _, userID := range team {
+				combined += courseHandicaps[userID]
+			}
+			teamHandicap := int(math.Round(combined * scale))
+			for _, userID := range team {
+				playing[userID] = teamHandicap
+			}
+		}
+		return playing
+	}
+
+	for _, userID := range roster.players() {
+		playing[userID] = int(math.Round(courseHandicaps[userID] * scale))
+	}
+	return playing
+}
+
+// allocateStrokes spreads each player's strokes across the match's holes, hardest stroke index first.
+// In full_difference mode every player plays off the lowest playing handicap in the match.
+func allocateStrokes(match *models.Match, playing map[string]int, holes []int, strokeIndex map[int]int) StrokeAllocation {
+	if match.HandicapMode == "" || match.HandicapMode == models.HandicapGross || len(holes) == 0 {
+		return nil
+	}
+
+	received := make(map[string]int, len(playing))
+	for userID, handicap := range playing {
+		received[userID] = handicap
+	}
+	if match.HandicapMode == models.HandicapFullDifference {
+		lowest := math.MaxInt
+		for _, handicap := range playing {
+			if handicap < lowest {
+				lowest = handicap
+			}
+		}
+		for userID := range received {
+			received[userID] -= lowest
+		}
+	}
+
+	// Rank the match's holes from hardest to easiest
+	ordered := append([]int{}, holes...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return strokeIndex[ordered[i]] < strokeIndex[ordered[j]]
+	})
+
+	allocation := make(StrokeAllocation, len(ordered))
+	for _, hole := range ordered {
+		allocation[hole] = make(map[string]int)
+	}
+
+	for userID, strokes := range received {
+		for i := 0; i < abs(strokes); i++ {
+			if strokes > 0 {
+				allocation[ordered[i%len(ordered)]][userID]++
+			} else {
+				// Plus handicaps give strokes back, starting on the easiest hole
+				allocation[ordered[len(ordered)-1-i%len(ordered)]][userID]--
+			}
+		}
+	}
+
+	return allocation
+}
+
+// defaultStrokeIndexes ranks holes by number when no course data is available
+func defaultStrokeIndexes(holes []int) map[int]int {
+	strokeIndex := make(map[int]int, len(holes))
+	for _, hole := range holes {
+		strokeIndex[hole] = hole
+	}
+	return strokeIndex
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,87 @@
+package scoring
+
+import (
+	"reflect"
+	"testing"
+
+	"mayhamapi/models"
+)
+
+func TestAllocateStrokes(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        string
+		playing     map[string]int
+		holes       []int
+		strokeIndex map[int]int      // nil ranks holes by number
+		want        StrokeAllocation // strokes received, leaving out holes and players with none
+	}{
+		{
+			name: "gross gives no strokes", mode: models.HandicapGross,
+			playing: map[string]int{"a1": 10, "b1": 2}, holes: []int{1, 2, 3},
+			want: nil,
+		},
+		{
+			name: "full handicap on the hardest holes", mode: models.HandicapFullHandicap,
+			playing: map[string]int{"a1": 3, "b1": 1}, holes: []int{1, 2, 3, 4, 5, 6, 7, 8, 9},
+			want: StrokeAllocation{1: {"a1": 1, "b1": 1}, 2: {"a1": 1}, 3: {"a1": 1}},
+		},
+		{
+			name: "full difference plays off the lowest", mode: models.HandicapFullDifference,
+			playing: map[string]int{"a1": 5, "b1": 2}, holes: []int{1, 2, 3, 4, 5, 6, 7, 8, 9},
+			want: StrokeAllocation{1: {"a1": 1}, 2: {"a1": 1}, 3: {"a1": 1}},
+		},
+		{
+			name: "stroke index decides the holes", mode: models.HandicapFullHandicap,
+			playing: map[string]int{"a1": 2}, holes: []int{1, 2, 3},
+			strokeIndex: map[int]int{1: 5, 2: 1, 3: 3},
+			want:        StrokeAllocation{2: {"a1": 1}, 3: {"a1": 1}},
+		},
+		{
+			name: "more strokes than holes go round again", mode: models.HandicapFullHandicap,
+			playing: map[string]int{"a1": 4}, holes: []int{1, 2, 3},
+			want: StrokeAllocation{1: {"a1": 2}, 2: {"a1": 1}, 3: {"a1": 1}},
+		},
+		{
+			name: "plus handicap gives back on the easiest hole", mode: models.HandicapFullHandicap,
+			playing: map[string]int{"a1": -1, "b1": 1}, holes: []int{1, 2, 3},
+			want: StrokeAllocation{1: {"b1": 1}, 3: {"a1": -1}},
+		},
+		{
+			name: "shotgun start ranks by stroke index, not order played", mode: models.HandicapFullHandicap,
+			playing: map[string]int{"a1": 2}, holes: []int{16, 17, 18, 1, 2},
+			want: StrokeAllocation{1: {"a1": 1}, 2: {"a1": 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strokeIndex := tt.strokeIndex
+			if strokeIndex == nil {
+				strokeIndex = defaultStrokeIndexes(tt.holes)
+			}
+			match := &models.Match{HandicapMode: tt.mode, Holes: len(tt.holes)}
+
+			allocation := allocateStrokes(match, tt.playing, tt.holes, strokeIndex)
+
+			var got StrokeAllocation
+			for hole, strokes := range allocation {
+				for userID, n := range strokes {
+					if n == 0 {
+						continue
+					}
+					if got == nil {
+						got = make(StrokeAllocation)
+					}
+					if got[hole] == nil {
+						got[hole] = make(map[string]int)
+					}
+					got[hole][userID] = n
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("allocateStrokes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+package scoring
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"mayhamapi/models"
+)
+
+// standingRecord is the running tally behind a team or individual standing
+type standingRecord struct {
+	pointsWon, pointsLost          float64
+	matchesWon, matchesLost, tied  int
+	holesWon, holesLost, holesTied int
+}
+
+func (r *standingRecord) matchesPlayed() int {
+	return r.matchesWon + r.matchesLost + r.tied
+}
+
+func (r *standingRecord) winPercentage() float64 {
+	if r.matchesPlayed() == 0 {
+		return 0
+	}
+	return (float64(r.matchesWon) + float64(r.tied)/2) / float64(r.matchesPlayed())
+}
+
+// addMatch records a completed match from one side's point of view
+func (r *standingRecord) addMatch(points, against float64) {
+	r.pointsWon += points
+	r.pointsLost += against
+	switch {
+	case points > against:
+		r.matchesWon++
+	case points < against:
+		r.matchesLost++
+	default:
+		r.tied++
+	}
+}
+
+// countsHoles reports whether the match's holes count towards hole records. Formats decided on
+// total score, like stroke play and Stableford, never name a hole winner, so their holes don't.
+func (s *ScoringService) countsHoles(match *models.Match) (bool, error) {
+	_, scorer, err := s.scorerForMatch(match)
+	if err != nil {
+		return false, err
+	}
+	_, total := scorer.(totalScorer)
+	return !total, nil
+}
+
+// addHole records a decided hole for the side playing as teamID
+func (r *standingRecord) addHole(result models.HoleResult, teamID string) {
+	switch {
+	case result.WinnerTeamID == nil:
+		r.holesTied++
+	case *result.WinnerTeamID == teamID:
+		r.holesWon++
+	default:
+		r.holesLost++
+	}
+}
+
+// leaderboardTiebreakers are the ways standings can be ordered. Each is compared highest first,
+// in the order given, and sides still level after all of them share a rank.
+var leaderboardTiebreakers = map[string]func(*standingRecord) float64{
+	"points":         func(r *standingRecord) float64 { return r.pointsWon },
+	"win_percentage": func(r *standingRecord) float64 { return r.winPercentage() },
+	"matches_won":    func(r *standingRecord) float64 { return float64(r.matchesWon) },
+	"holes_diff":     func(r *standingRecord) float64 { return float64(r.holesWon - r.holesLost) },
+	"holes_won":      func(r *standingRecord) float64 { return float64(r.holesWon) },
+}
+
+var (
+	defaultTeamTiebreakers       = []string{"points", "matches_won", "holes_diff"}
+	defaultIndividualTiebreakers = []string{"points", "win_percentage", "holes_diff"}
+)
+
+// leaderboardMatch is a match's contribution to the standings; results is empty for
+// matches whose holes don't count towards hole records
+type leaderboardMatch struct {
+	match   *models.Match
+	results []models.HoleResult
+}
+
+// CalculateLeaderboard builds a tournament's team or individual standings, ordered by the given
+// tiebreakers (or the view's defaults when none are given). Individual standings come from
+// player_stats.
+func (s *ScoringService) CalculateLeaderboard(tournamentID, view string, tiebreakers []string) (*models.Leaderboard, error) {
+	if len(tiebreakers) == 0 {
+		tiebreakers = defaultTeamTiebreakers
+		if view == "individual" {
+			tiebreakers = defaultIndividualTiebreakers
+		}
+	}
+	for _, name := range tiebreakers {
+		if _, ok := leaderboardTiebreakers[name]; !ok {
+			return nil, fmt.Errorf("unknown tiebreaker %q", name)
+		}
+	}
+
+	if _, err := s.repo.GetTournament(tournamentID); err != nil {
+		return nil, err
+	}
+	teams, err := s.repo.GetTeamsByTournament(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+
+	leaderboard := &models.Leaderboard{TournamentID: tournamentID, View: view, Tiebreakers: tiebreakers}
+	if view == "individual" {
+		leaderboard.IndividualStandings, err = s.individualStandings(tournamentID, teams, tiebreakers)
+		if err != nil {
+			return nil, err
+		}
+		return leaderboard, nil
+	}
+
+	matches, err := s.tournamentMatches(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	played := make([]leaderboardMatch, 0, len(matches))
+	for i := range matches {
+		entry := leaderboardMatch{match: &matches[i]}
+		counts, err := s.countsHoles(&matches[i])
+		if err != nil {
+			return nil, err
+		}
+		if counts {
+			if entry.results, err = s.repo.GetHoleResults(matches[i].ID); err != nil {
+				return nil, err
+			}
+		}
+		played = append(played, entry)
+	}
+
+	leaderboard.TeamStandings = teamStandings(teams, played, tiebreakers)
+	return leaderboard, nil
+}
+
+func teamStandings(teams []models.Team, matches []leaderboardMatch, tiebreakers []string) []models.TeamStanding {
+	records := make(map[string]*standingRecord, len(teams))
+	for _, team := range teams {
+		records[team.ID] = &standingRecord{}
+	}
+
+	for _, entry := range matches {
+		team1, team2 := records[entry.match.Team1ID], records[entry.match.Team2ID]
+		if team1 == nil || team2 == nil {
+			continue
+		}
+		if entry.match.Status == "completed" {
+			team1.addMatch(entry.match.Team1Points, entry.match.Team2Points)
+			team2.addMatch(entry.match.Team2Points, entry.match.Team1Points)
+		}
+		for _, result := range entry.results {
+			team1.addHole(result, entry.match.Team1ID)
+			team2.addHole(result, entry.match.Team2ID)
+		}
+	}
+
+	ordered := make([]*standingRecord, len(teams))
+	for i, team := range teams {
+		ordered[i] = records[team.ID]
+	}
+	ranks := rankStandings(ordered, func(i int) string { return teams[i].Name }, tiebreakers)
+
+	standings := make([]models.TeamStanding, 0, len(teams))
+	for _, i := range ranks.order {
+		r := ordered[i]
+		standings = append(standings, models.TeamStanding{
+			Rank:        ranks.rank[i],
+			Team:        teams[i],
+			PointsWon:   r.pointsWon,
+			PointsLost:  r.pointsLost,
+			MatchesWon:  r.matchesWon,
+			MatchesLost: r.matchesLost,
+			MatchesTied: r.tied,
+			HolesWon:    r.holesWon,
+			HolesLost:   r.holesLost,
+			HolesTied:   r.holesTied,
+		})
+	}
+	return standings
+}
+
+func (s *ScoringService) individualStandings(tournamentID string, teams []models.Team, tiebreakers []string) ([]models.IndividualStanding, error) {
+	teamsByID := make(map[string]models.Team, len(teams))
+	for _, team := range teams {
+		teamsByID[team.ID] = team
+	}
+
+	stats, err := s.repo.GetPlayerStatsByTournament(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	var userIDs []string
+	playerTeam := make(map[string]string, len(stats))
+	records := make(map[string]*standingRecord, len(stats))
+	for _, row := range stats {
+		userIDs = append(userIDs, row.UserID)
+		playerTeam[row.UserID] = row.TeamID
+		records[row.UserID] = statsRecord(row)
+	}
+
+	// Team members added since the stats were last written haven't played yet
+	for _, team := range teams {
+		members, err := s.repo.GetTeamMembers(team.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members {
+			if _, ok := records[member.UserID]; !ok {
+				userIDs = append(userIDs, member.UserID)
+				playerTeam[member.UserID] = team.ID
+				records[member.UserID] = &standingRecord{}
+			}
+		}
+	}
+
+	players := make(map[string]models.PlayerSummary, len(userIDs))
+	if len(userIDs) > 0 {
+		users, err := s.repo.GetUsersByIDs(userIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range users {
+			players[user.ID] = models.PlayerSummary{ID: user.ID, Name: user.Name, Handicap: user.Handicap}
+		}
+	}
+
+	ordered := make([]*standingRecord, len(userIDs))
+	for i, userID := range userIDs {
+		ordered[i] = records[userID]
+	}
+	ranks := rankStandings(ordered, func(i int) string { return players[userIDs[i]].Name }, tiebreakers)
+
+	standings := make([]models.IndividualStanding, 0, len(userIDs))
+	for _, i := range ranks.order {
+		userID, r := userIDs[i], ordered[i]
+		standings = append(standings, models.IndividualStanding{
+			Rank:          ranks.rank[i],
+			User:          players[userID],
+			Team:          teamsByID[playerTeam[userID]],
+			PointsWon:     r.pointsWon,
+			PointsLost:    r.pointsLost,
+			MatchesPlayed: r.matchesPlayed(),
+			MatchesWon:    r.matchesWon,
+			MatchesLost:   r.matchesLost,
+			MatchesTied:   r.tied,
+			HolesWon:      r.holesWon,
+			HolesLost:     r.holesLost,
+			HolesTied:     r.holesTied,
+			WinPercentage: math.Round(r.winPercentage()*1000) / 1000,
+		})
+	}
+	return standings, nil
+}
+
+// standingRanks is the display order of a set of records, and each record's rank
+type standingRanks struct {
+	order []int
+	rank  []int
+}
+
+// rankStandings orders records by the tiebreakers, then by name for a stable display.
+// Records level on every tiebreaker share a rank.
+func rankStandings(records []*standingRecord, name func(int) string, tiebreakers []string) standingRanks {
+	compare := func(a, b int) int {
+		for _, tiebreaker := range tiebreakers {
+			key := leaderboardTiebreakers[tiebreaker]
+			if x, y := key(records[a]), key(records[b]); x != y {
+				if x > y {
+					return -1
+				}
+				return 1
+			}
+		}
+		return 0
+	}
+
+	ranks := standingRanks{order: make([]int, len(records)), rank: make([]int, len(records))}
+	for i := range records {
+		ranks.order[i] = i
+	}
+	sort.SliceStable(ranks.order, func(i, j int) bool {
+		a, b := ranks.order[i], ranks.order[j]
+		if c := compare(a, b); c != 0 {
+			return c < 0
+		}
+		return name(a) < name(b)
+	})
+
+	for pos, i := range ranks.order {
+		ranks.rank[i] = pos + 1
+		if pos > 0 && compare(ranks.order[pos-1], i) == 0 {
+			ranks.rank[i] = ranks.rank[ranks.order[pos-1]]
+		}
+	}
+	return ranks
+}
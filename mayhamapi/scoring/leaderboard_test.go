@@ -0,0 +1,92 @@
+package scoring
+
+import (
+	"reflect"
+	"testing"
+
+	"mayhamapi/models"
+)
+
+func TestRankStandings(t *testing.T) {
+	names := []string{"Cobras", "Albatrosses", "Birdies", "Dragons"}
+	records := []*standingRecord{
+		{pointsWon: 3, matchesWon: 3, holesWon: 10, holesLost: 8},
+		{pointsWon: 3, matchesWon: 2, tied: 2, holesWon: 12, holesLost: 8},
+		{pointsWon: 3, matchesWon: 3, holesWon: 10, holesLost: 8},
+		{pointsWon: 1, matchesWon: 1, holesWon: 15, holesLost: 2},
+	}
+
+	tests := []struct {
+		name        string
+		tiebreakers []string
+		order       []string
+		ranks       []int // in order
+	}{
+		{"points alone", []string{"points"}, []string{"Albatrosses", "Birdies", "Cobras", "Dragons"}, []int{1, 1, 1, 4}},
+		{"matches won breaks the tie", []string{"points", "matches_won"}, []string{"Birdies", "Cobras", "Albatrosses", "Dragons"}, []int{1, 1, 3, 4}},
+		{"holes difference breaks the tie", []string{"points", "holes_diff"}, []string{"Albatrosses", "Birdies", "Cobras", "Dragons"}, []int{1, 2, 2, 4}},
+		{"first tiebreaker decides", []string{"holes_diff", "points"}, []string{"Dragons", "Albatrosses", "Birdies", "Cobras"}, []int{1, 2, 3, 3}},
+		{"no tiebreakers", nil, []string{"Albatrosses", "Birdies", "Cobras", "Dragons"}, []int{1, 1, 1, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranks := rankStandings(records, func(i int) string { return names[i] }, tt.tiebreakers)
+
+			var order []string
+			var got []int
+			for _, i := range ranks.order {
+				order = append(order, names[i])
+				got = append(got, ranks.rank[i])
+			}
+			if !reflect.DeepEqual(order, tt.order) || !reflect.DeepEqual(got, tt.ranks) {
+				t.Errorf("order %v ranks %v, want %v ranks %v", order, got, tt.order, tt.ranks)
+			}
+		})
+	}
+}
+
+func TestTeamStandings(t *testing.T) {
+	t1, t2 := "t1", "t2"
+	teams := []models.Team{{ID: "t1", Name: "Team 1"}, {ID: "t2", Name: "Team 2"}, {ID: "t3", Name: "Team 3"}}
+	matches := []leaderboardMatch{
+		{
+			match:   &models.Match{Team1ID: "t1", Team2ID: "t2", Status: "completed", Team1Points: 1},
+			results: []models.HoleResult{{WinnerTeamID: &t1}, {WinnerTeamID: &t1}, {}},
+		},
+		{
+			match: &models.Match{Team1ID: "t2", Team2ID: "t1", Status: "completed", Team1Points: 0.5, Team2Points: 0.5},
+		},
+		{
+			// Holes in an unfinished match count, but the match doesn't yet
+			match:   &models.Match{Team1ID: "t2", Team2ID: "t1", Status: "in_progress"},
+			results: []models.HoleResult{{WinnerTeamID: &t2}},
+		},
+		{
+			// Matches against teams outside the tournament are left out
+			match: &models.Match{Team1ID: "t1", Team2ID: "other", Status: "completed", Team1Points: 1},
+		},
+	}
+
+	standings := teamStandings(teams, matches, defaultTeamTiebreakers)
+
+	type line struct {
+		team               string
+		rank               int
+		won, lost          float64
+		mWon, mLost, mTied int
+		hWon, hLost, hTied int
+	}
+	var got []line
+	for _, s := range standings {
+		got = append(got, line{s.Team.ID, s.Rank, s.PointsWon, s.PointsLost, s.MatchesWon, s.MatchesLost, s.MatchesTied, s.HolesWon, s.HolesLost, s.HolesTied})
+	}
+	want := []line{
+		{"t1", 1, 1.5, 0.5, 1, 0, 1, 2, 1, 1},
+		{"t2", 2, 0.5, 1.5, 0, 1, 1, 1, 2, 1},
+		{"t3", 3, 0, 0, 0, 0, 0, 0, 0, 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("standings = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,252 @@
+package scoring
+
+import (
+	"fmt"
+	"strconv"
+
+	"mayhamapi/models"
+)
+
+// nassauPressDown is how far behind a side must be in a bet before it can press
+const nassauPressDown = 2
+
+// nassauScorer plays each hole as match play on the low (net) ball per side. The
+// front nine, back nine and overall are then settled as separate bets.
+type nassauScorer struct {
+	autoPress bool
+}
+
+func (nassauScorer) ScoreHole(hole *HoleInput) (*HoleResult, error) {
+	if len(hole.Team1) == 0 || len(hole.Team2) == 0 {
+		return nil, fmt.Errorf("insufficient scores for nassau")
+	}
+
+	return decideHole(hole, hole.minNet(hole.Team1), hole.minNet(hole.Team2)), nil
+}
+
+// Segment is one Nassau bet: the front nine, back nine, overall, or a press.
+// Each is worth one point to the winner, or half a point each when halved.
+type Segment struct {
+	Name           string  `json:"name"` // front, back, overall, front press or back press
+	StartHole      int     `json:"start_hole"`
+	EndHole        int     `json:"end_hole"`
+	PressedBy      *string `json:"pressed_by,omitempty"` // team that called the press
+	Team1Up        int     `json:"team1_up"`
+	HolesCompleted int     `json:"holes_completed"`
+	HolesRemaining int     `json:"holes_remaining"`
+	Complete       bool    `json:"complete"`
+	WinnerTeamID   *string `json:"winner_team_id"`
+	Team1Points    float64 `json:"team1_points"`
+	Team2Points    float64 `json:"team2_points"`
+	ResultText     string  `json:"result_text"`
+
+	first, last int // positions in the match's hole order
+	nine        int // 0 for the front, 1 for the back, -1 for the overall bet
+}
+
+func (seg *Segment) covers(position int) bool {
+	return position >= seg.first && position <= seg.last
+}
+
+func (seg *Segment) apply(match *models.Match, result HoleResult) {
+	if result.WinnerTeamID != nil {
+		if *result.WinnerTeamID == match.Team1ID {
+			seg.Team1Up++
+		} else {
+			seg.Team1Up--
+		}
+	}
+	seg.HolesCompleted++
+	seg.HolesRemaining--
+
+	if seg.HolesRemaining == 0 || abs(seg.Team1Up) > seg.HolesRemaining {
+		seg.Complete = true
+		switch {
+		case seg.Team1Up > 0:
+			seg.WinnerTeamID = &match.Team1ID
+			seg.Team1Points = 1
+		case seg.Team1Up < 0:
+			seg.WinnerTeamID = &match.Team2ID
+			seg.Team2Points = 1
+		default:
+			seg.Team1Points, seg.Team2Points = 0.5, 0.5
+		}
+	}
+
+	seg.ResultText = resultText(&MatchStatus{
+		Team1Up:       seg.Team1Up,
+		MatchComplete: seg.Complete,
+		ClosedOut:     seg.Complete && seg.HolesRemaining > 0,
+		HolesUnplayed: seg.HolesRemaining,
+		ScoringUnit:   "holes",
+	})
+}
+
+// nassauSegments settles the front, back and overall bets plus any presses from a
+// match's hole results. holes is the match's hole order; manual presses are keyed by
+// their starting hole, and auto presses start on the hole after a side goes 2 down in
+// the latest bet on a nine.
+func nassauSegments(match *models.Match, holes []int, results []HoleResult, presses []models.Press, autoPress bool) []Segment {
+	if len(holes) < 2 {
+		return nil
+	}
+
+	half := len(holes) / 2
+	newSegment := func(name string, first, last, nine int) *Segment {
+		return &Segment{
+			Name:           name,
+			StartHole:      holes[first],
+			EndHole:        holes[last],
+			HolesRemaining: last - first + 1,
+			ResultText:     "A/S",
+			first:          first,
+			last:           last,
+			nine:           nine,
+		}
+	}
+	nines := []*Segment{newSegment("front", 0, half-1, 0), newSegment("back", half, len(holes)-1, 1)}
+	segments := []*Segment{nines[0], nines[1], newSegment("overall", 0, len(holes)-1, -1)}
+
+	// latest is the most recent bet on each nine; presses are measured against it
+	latest := []*Segment{nines[0], nines[1]}
+	pressed := make(map[*Segment]bool)
+	press := func(position int, teamID string) {
+		nine := nines[0]
+		if position >= half {
+			nine = nines[1]
+		}
+		seg := newSegment(nine.Name+" press", position, nine.last, nine.nine)
+		seg.PressedBy = &teamID
+		pressed[latest[nine.nine]] = true
+		latest[nine.nine] = seg
+		segments = append(segments, seg)
+	}
+
+	position := make(map[int]int, len(holes))
+	for i, holeNum := range holes {
+		position[holeNum] = i
+	}
+	manual := make(map[int]models.Press)
+	for _, p := range presses {
+		if i, ok := position[p.StartHole]; ok {
+			manual[i] = p
+		}
+	}
+	played := make(map[int]HoleResult, len(results))
+	for _, result := range results {
+		played[position[result.HoleNumber]] = result
+	}
+
+	for i := range holes {
+		if p, ok := manual[i]; ok {
+			press(i, p.TeamID)
+		}
+
+		result, ok := played[i]
+		if !ok {
+			continue
+		}
+		for _, seg := range segments {
+			if !seg.Complete && seg.covers(i) {
+				seg.apply(match, result)
+			}
+		}
+
+		nine := 0
+		if i >= half {
+			nine = 1
+		}
+		bet := latest[nine]
+		_, manualNext := manual[i+1]
+		if autoPress && !pressed[bet] && !manualNext && abs(bet.Team1Up) >= nassauPressDown && i < nines[nine].last {
+			trailing := match.Team1ID
+			if bet.Team1Up > 0 {
+				trailing = match.Team2ID
+			}
+			press(i+1, trailing)
+		}
+	}
+
+	breakdown := make([]Segment, 0, len(segments))
+	for _, seg := range segments {
+		breakdown = append(breakdown, *seg)
+	}
+	return breakdown
+}
+
+// nassauResultText shows the Nassau points, leader first, e.g. "2-1" or "2.5-0.5"
+func nassauResultText(team1Points, team2Points float64) string {
+	high, low := team1Points, team2Points
+	if low > high {
+		high, low = low, high
+	}
+	return strconv.FormatFloat(high, 'f', -1, 64) + "-" + strconv.FormatFloat(low, 'f', -1, 64)
+}
+
+// ValidatePress checks a manual press can be called: the match must be a Nassau, the
+// press must start on a hole that hasn't been played yet, and the calling side must be
+// at least 2 down in the latest bet on that nine.
+func (s *ScoringService) ValidatePress(match *models.Match, teamID string, startHole int) error {
+	if teamID != match.Team1ID && teamID != match.Team2ID {
+		return fmt.Errorf("team is not playing in this match")
+	}
+
+	ctx, err := s.loadMatchContext(match)
+	if err != nil {
+		return err
+	}
+	if _, ok := ctx.scorer.(nassauScorer); !ok {
+		return fmt.Errorf("presses are only available in Nassau matches")
+	}
+
+	scores, err := s.repo.GetMatchScores(match.ID)
+	if err != nil {
+		return err
+	}
+	status, err := s.CalculateMatchStatus(match, scores)
+	if err != nil {
+		return err
+	}
+
+	start := -1
+	for i, holeNum := range ctx.holes {
+		if holeNum == startHole {
+			start = i
+		}
+	}
+	if start < 0 {
+		return fmt.Errorf("hole %d is not part of this match", startHole)
+	}
+	for _, result := range status.HoleResults {
+		if result.HoleNumber == startHole {
+			return fmt.Errorf("hole %d has already been played", startHole)
+		}
+	}
+
+	nine := 0
+	if start >= len(ctx.holes)/2 {
+		nine = 1
+	}
+	var bet *Segment
+	for i := range status.Segments {
+		if seg := &status.Segments[i]; seg.nine == nine && seg.first < start {
+			bet = seg
+		}
+		if seg := status.Segments[i]; seg.PressedBy != nil && seg.first == start {
+			return fmt.Errorf("a press already starts on hole %d", startHole)
+		}
+	}
+	if bet == nil {
+		return fmt.Errorf("a press can't start on the first hole of a nine")
+	}
+
+	down := bet.Team1Up
+	if teamID == match.Team2ID {
+		down = -down
+	}
+	if -down < nassauPressDown {
+		return fmt.Errorf("a side must be at least %d down in the %s bet to press", nassauPressDown, bet.Name)
+	}
+
+	return nil
+}
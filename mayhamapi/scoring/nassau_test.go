@@ -0,0 +1,172 @@
+package scoring
+
+import (
+	"reflect"
+	"testing"
+
+	"mayhamapi/models"
+)
+
+// holeWinners gives hole results for holes 1 onwards, one character per hole: 1 or 2 for
+// the winning side, - for a halved hole and . for a hole not played yet
+func holeWinners(match *models.Match, holes string) []HoleResult {
+	var results []HoleResult
+	for i, c := range holes {
+		result := HoleResult{HoleNumber: i + 1}
+		switch c {
+		case '1':
+			result.WinnerTeamID = &match.Team1ID
+		case '2':
+			result.WinnerTeamID = &match.Team2ID
+		case '.':
+			continue
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+func TestNassauSegments(t *testing.T) {
+	type segment struct {
+		name        string
+		start       int
+		pressedBy   string
+		team1Up     int
+		complete    bool
+		team1Points float64
+		team2Points float64
+		resultText  string
+	}
+
+	tests := []struct {
+		name      string
+		holes     string
+		presses   []models.Press
+		autoPress bool
+		want      []segment
+	}{
+		{
+			name:  "front and back split, overall halved",
+			holes: "1--------2--------",
+			want: []segment{
+				{"front", 1, "", 1, true, 1, 0, "1 UP"},
+				{"back", 10, "", -1, true, 0, 1, "1 UP"},
+				{"overall", 1, "", 0, true, 0.5, 0.5, "Halved"},
+			},
+		},
+		{
+			name:  "closed-out nine keeps counting toward the overall",
+			holes: "11111-------------",
+			want: []segment{
+				{"front", 1, "", 5, true, 1, 0, "5&4"},
+				{"back", 10, "", 0, true, 0.5, 0.5, "Halved"},
+				{"overall", 1, "", 5, true, 1, 0, "5&4"},
+			},
+		},
+		{
+			name:  "in progress",
+			holes: "1--...............",
+			want: []segment{
+				{"front", 1, "", 1, false, 0, 0, "1 UP"},
+				{"back", 10, "", 0, false, 0, 0, "A/S"},
+				{"overall", 1, "", 1, false, 0, 0, "1 UP"},
+			},
+		},
+		{
+			name:    "manual press",
+			holes:   "221---------------",
+			presses: []models.Press{{TeamID: "t1", StartHole: 3}},
+			want: []segment{
+				{"front", 1, "", -1, true, 0, 1, "1 UP"},
+				{"back", 10, "", 0, true, 0.5, 0.5, "Halved"},
+				{"overall", 1, "", -1, true, 0, 1, "1 UP"},
+				{"front press", 3, "t1", 1, true, 1, 0, "1 UP"},
+			},
+		},
+		{
+			name:      "auto press after going 2 down",
+			holes:     "22----------------",
+			autoPress: true,
+			want: []segment{
+				{"front", 1, "", -2, true, 0, 1, "2&1"},
+				{"back", 10, "", 0, true, 0.5, 0.5, "Halved"},
+				{"overall", 1, "", -2, true, 0, 1, "2&1"},
+				{"front press", 3, "t1", 0, true, 0.5, 0.5, "Halved"},
+			},
+		},
+		{
+			name:      "auto press on the back nine",
+			holes:     "---------11-------",
+			autoPress: true,
+			want: []segment{
+				{"front", 1, "", 0, true, 0.5, 0.5, "Halved"},
+				{"back", 10, "", 2, true, 1, 0, "2&1"},
+				{"overall", 1, "", 2, true, 1, 0, "2&1"},
+				{"back press", 12, "t2", 0, true, 0.5, 0.5, "Halved"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := testMatch(18)
+			segments := nassauSegments(match, match.HolesOn(18), holeWinners(match, tt.holes), tt.presses, tt.autoPress)
+
+			var got []segment
+			for _, seg := range segments {
+				pressedBy := ""
+				if seg.PressedBy != nil {
+					pressedBy = *seg.PressedBy
+				}
+				got = append(got, segment{seg.Name, seg.StartHole, pressedBy, seg.Team1Up, seg.Complete, seg.Team1Points, seg.Team2Points, seg.ResultText})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("nassauSegments =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNassauSegmentsTooShort(t *testing.T) {
+	match := testMatch(1)
+	if segments := nassauSegments(match, match.HolesOn(18), nil, nil, false); segments != nil {
+		t.Errorf("nassauSegments = %+v, want none", segments)
+	}
+}
+
+func TestMatchStatusCountsNewPress(t *testing.T) {
+	won, lost, halved := [2]int{3, 4}, [2]int{5, 4}, [2]int{4, 4}
+	holes := [][2]int{lost, lost, won}
+	for len(holes) < 18 {
+		holes = append(holes, halved)
+	}
+	var scores []models.Score
+	for _, holeScores := range singles(holes...) {
+		scores = append(scores, holeScores...)
+	}
+
+	tests := []struct {
+		name                     string
+		presses                  []models.Press
+		segments                 int
+		team1Points, team2Points float64
+	}{
+		{"without the press", nil, 3, 0.5, 2.5},
+		{"with a press from hole 3", []models.Press{{TeamID: "t1", StartHole: 3}}, 4, 1.5, 2.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := singlesContext(18, nassauScorer{}).matchStatus(scores, nil, tt.presses)
+			if err != nil {
+				t.Fatalf("matchStatus: %v", err)
+			}
+			if len(status.Segments) != tt.segments {
+				t.Errorf("%d segments, want %d", len(status.Segments), tt.segments)
+			}
+			if status.Team1SegmentPoints != tt.team1Points || status.Team2SegmentPoints != tt.team2Points {
+				t.Errorf("segment points = %v-%v, want %v-%v", status.Team1SegmentPoints, status.Team2SegmentPoints, tt.team1Points, tt.team2Points)
+			}
+		})
+	}
+}
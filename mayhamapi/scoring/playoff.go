@@ -0,0 +1,75 @@
+package scoring
+
+import (
+	"fmt"
+
+	"mayhamapi/models"
+)
+
+// firstPlayoffHole is the number given to the first playoff hole: one past both the
+// match's length and the highest course hole it plays, so it can't be mistaken for a
+// regulation hole
+func (ctx *matchContext) firstPlayoffHole() int {
+	last := ctx.match.Holes
+	for _, holeNum := range ctx.holes {
+		if holeNum > last {
+			last = holeNum
+		}
+	}
+	return last + 1
+}
+
+// playoffCourseHole maps a playoff hole number onto the course hole being replayed.
+// Playoffs go back around the match's holes in order, starting from its first hole.
+func (ctx *matchContext) playoffCourseHole(holeNumber int) int {
+	return ctx.holes[(holeNumber-ctx.firstPlayoffHole())%len(ctx.holes)]
+}
+
+// playOff scores sudden-death holes for a tied match that must produce a winner. The
+// first playoff hole won ends the match; until then it stays in progress. Par and
+// handicap strokes follow the course hole being replayed.
+func (ctx *matchContext) playOff(holeScores map[int][]models.Score, status *MatchStatus) error {
+	status.MatchComplete = false
+	status.InPlayoff = true
+	status.ResultText = "A/S"
+	status.PlayoffHoles = []HoleResult{}
+
+	for holeNum := ctx.firstPlayoffHole(); len(holeScores[holeNum]) > 0 || ctx.concededHoles[holeNum] != ""; holeNum++ {
+		courseHole := ctx.playoffCourseHole(holeNum)
+		hole := splitHoleScores(ctx.match, ctx.roster, holeNum, holeScores[holeNum])
+		hole.Strokes = ctx.allocation.forHole(courseHole)
+		hole.Par = ctx.par(courseHole)
+		if !ctx.holeDecided(hole) {
+			return nil
+		}
+
+		result, err := ctx.scoreHole(hole)
+		if err != nil {
+			return fmt.Errorf("failed to calculate playoff hole result: %w", err)
+		}
+
+		// Total formats don't award holes, so decide the playoff hole on the team scores
+		if totals, ok := ctx.scorer.(totalScorer); ok {
+			team1, team2 := *result.Team1Score, *result.Team2Score
+			if !totals.lowerWins() {
+				team1, team2 = team2, team1
+			}
+			if team1 < team2 {
+				result.WinnerTeamID = &ctx.match.Team1ID
+			} else if team2 < team1 {
+				result.WinnerTeamID = &ctx.match.Team2ID
+			}
+		}
+		status.PlayoffHoles = append(status.PlayoffHoles, *result)
+
+		if result.WinnerTeamID != nil {
+			status.MatchComplete = true
+			status.WinnerTeamID = result.WinnerTeamID
+			status.LeaderTeamID = result.WinnerTeamID
+			status.ResultText = fmt.Sprintf("1 UP (%d)", holeNum) // e.g. "1 UP (19)" for a win on the first extra hole
+			return nil
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package scoring
+
+import (
+	"reflect"
+	"testing"
+
+	"mayhamapi/models"
+)
+
+func TestFirstPlayoffHole(t *testing.T) {
+	tests := []struct {
+		name        string
+		match       models.Match
+		courseHoles int
+		want        int
+	}{
+		{"full round", models.Match{Holes: 18, StartingHole: 1}, 18, 19},
+		{"front nine", models.Match{Holes: 9, StartingHole: 1}, 18, 10},
+		{"back nine", models.Match{Holes: 9, StartingHole: 10}, 18, 19},
+		{"nine-hole course", models.Match{Holes: 9, StartingHole: 1}, 9, 10},
+		{"shotgun start wrapping past 18", models.Match{Holes: 6, StartingHole: 16}, 18, 19},
+		{"hole sequence", models.Match{Holes: 3, HoleSequence: []int{3, 1, 2}}, 18, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := &matchContext{match: &tt.match, holes: tt.match.HolesOn(tt.courseHoles)}
+			if got := ctx.firstPlayoffHole(); got != tt.want {
+				t.Errorf("firstPlayoffHole = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlayoffCourseHole(t *testing.T) {
+	match := &models.Match{Holes: 9, StartingHole: 10}
+	ctx := &matchContext{match: match, holes: match.HolesOn(18)}
+
+	var got []int
+	for holeNum := 19; holeNum <= 29; holeNum++ {
+		got = append(got, ctx.playoffCourseHole(holeNum))
+	}
+	// Playoffs replay the match's holes from its first, going back around after its last
+	want := []int{10, 11, 12, 13, 14, 15, 16, 17, 18, 10, 11}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("playoff course holes = %v, want %v", got, want)
+	}
+}
+
+func TestPlayOff(t *testing.T) {
+	won, lost, halved := [2]int{3, 4}, [2]int{5, 4}, [2]int{4, 4}
+	par := map[int]models.Hole{1: {HoleNumber: 1, Par: 4}, 2: {HoleNumber: 2, Par: 3}, 3: {HoleNumber: 3, Par: 5}}
+
+	tests := []struct {
+		name          string
+		scorer        FormatScorer
+		playoff       map[int][]models.Score // scores on holes 4 on, the first playoff hole
+		concededHoles map[int]string
+		allocation    StrokeAllocation
+		playoffHoles  int
+		complete      bool
+		winner        string
+		resultText    string
+	}{
+		{
+			name: "no playoff holes played yet", scorer: matchPlayScorer{},
+			resultText: "A/S",
+		},
+		{
+			name: "won on the first extra hole", scorer: matchPlayScorer{},
+			playoff:      singlesFrom(4, won),
+			playoffHoles: 1, complete: true, winner: "t1", resultText: "1 UP (4)",
+		},
+		{
+			name: "halved extra holes carry on", scorer: matchPlayScorer{},
+			playoff:      singlesFrom(4, halved, halved, lost),
+			playoffHoles: 3, complete: true, winner: "t2", resultText: "1 UP (6)",
+		},
+		{
+			name: "a hole still being played isn't counted", scorer: matchPlayScorer{},
+			playoff:    map[int][]models.Score{4: postScores(4, map[string]int{"a1": 3})},
+			resultText: "A/S",
+		},
+		{
+			name: "strokes follow the course hole replayed", scorer: matchPlayScorer{},
+			playoff:      singlesFrom(4, lost),
+			allocation:   StrokeAllocation{1: {"a1": 1}},
+			playoffHoles: 1, resultText: "A/S",
+		},
+		{
+			name: "conceded extra hole", scorer: matchPlayScorer{},
+			concededHoles: map[int]string{4: "t1"},
+			playoffHoles:  1, complete: true, winner: "t2", resultText: "1 UP (4)",
+		},
+		{
+			name: "stroke play decides on the lower total", scorer: strokePlayScorer{},
+			playoff:      singlesFrom(4, lost),
+			playoffHoles: 1, complete: true, winner: "t2", resultText: "1 UP (4)",
+		},
+		{
+			name: "Stableford decides on the higher points", scorer: stablefordScorer{points: models.DefaultStablefordPoints},
+			playoff:      singlesFrom(4, won),
+			playoffHoles: 1, complete: true, winner: "t1", resultText: "1 UP (4)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := singlesContext(3, tt.scorer)
+			ctx.match.MustProduceWinner = true
+			ctx.course = par
+			ctx.allocation = tt.allocation
+			ctx.concededHoles = tt.concededHoles
+
+			// Regulation finished level
+			holeScores := singles(won, lost, halved)
+			for holeNum, scores := range tt.playoff {
+				holeScores[holeNum] = scores
+			}
+			status := &MatchStatus{MatchComplete: true, ResultText: "Halved"}
+			if err := ctx.playOff(holeScores, status); err != nil {
+				t.Fatalf("playOff: %v", err)
+			}
+
+			winner := ""
+			if status.WinnerTeamID != nil {
+				winner = *status.WinnerTeamID
+			}
+			if !status.InPlayoff || len(status.PlayoffHoles) != tt.playoffHoles || status.MatchComplete != tt.complete ||
+				winner != tt.winner || status.ResultText != tt.resultText {
+				t.Errorf("in playoff %v, %d playoff holes, complete %v, winner %q, %q; want in playoff, %d, %v, %q, %q",
+					status.InPlayoff, len(status.PlayoffHoles), status.MatchComplete, winner, status.ResultText,
+					tt.playoffHoles, tt.complete, tt.winner, tt.resultText)
+			}
+		})
+	}
+}
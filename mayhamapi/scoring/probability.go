@@ -0,0 +1,296 @@
+package scoring
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"mayhamapi/models"
+)
+
+// Win probabilities come from a simple per-hole model. Evenly matched sides halve
+// holeHalveRate of holes and split the rest; each stroke per hole of expected advantage
+// (the sides' handicap gap, less any strokes given) moves holeStrokeEdge of win
+// probability from one side to the other, up to maxHoleEdge.
+const (
+	holeHalveRate  = 0.4
+	holeStrokeEdge = 0.3
+	maxHoleEdge    = 0.25
+	// holeMarginSD is the spread of one hole's stroke or points margin in total formats
+	holeMarginSD = 1.4
+	// cupSimulations is how many times the open matches are played out to estimate the cup odds
+	cupSimulations = 10000
+	// startingOddsTTL is how long an unscored match's pre-match odds are reused before being
+	// worked out again, picking up roster and handicap changes
+	startingOddsTTL = 5 * time.Minute
+)
+
+// oddsCache holds the pre-match odds of unscored matches by match ID, so the cup odds don't
+// rescore every open match on each request. Scored matches have their odds stored.
+type oddsCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedOdds
+}
+
+type cachedOdds struct {
+	probability *models.MatchProbability
+	expires     time.Time
+}
+
+func newOddsCache() *oddsCache {
+	return &oddsCache{entries: make(map[string]cachedOdds)}
+}
+
+// startingOdds returns an unscored match's pre-match odds, or even odds when its roster or
+// handicaps can't be loaded yet, e.g. while the tournament is still being set up. With no
+// scores there's nothing to score, so the odds come straight from the match's context.
+func (s *ScoringService) startingOdds(match *models.Match) *models.MatchProbability {
+	now := time.Now()
+	s.odds.mu.Lock()
+	cached, ok := s.odds.entries[match.ID]
+	s.odds.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.probability
+	}
+
+	probability := evenOdds(match)
+	if ctx, err := s.loadMatchContext(match); err == nil {
+		probability = ctx.probability(&MatchStatus{})
+	}
+
+	s.odds.mu.Lock()
+	s.odds.evictExpired(now)
+	s.odds.entries[match.ID] = cachedOdds{probability: probability, expires: now.Add(startingOddsTTL)}
+	s.odds.mu.Unlock()
+	return probability
+}
+
+// evictExpired drops entries that have outlived startingOddsTTL, so matches that have since
+// been scored or deleted don't stay cached for the life of the server. Call it with mu held.
+func (c *oddsCache) evictExpired(now time.Time) {
+	for matchID, cached := range c.entries {
+		if !now.Before(cached.expires) {
+			delete(c.entries, matchID)
+		}
+	}
+}
+
+// evenOdds is the starting probability of a match between evenly matched sides. With no
+// strokes given, only the number of holes matters, not which course holes they are.
+func evenOdds(match *models.Match) *models.MatchProbability {
+	ctx := &matchContext{match: match, holes: match.HolesOn(models.DefaultCourseHoles), roster: &MatchRoster{}}
+	return roundProbability(ctx.holesProbability(0, ctx.holes))
+}
+
+// probability estimates how the match will finish from its current margin and the holes left.
+// A Nassau is estimated from its overall bet.
+func (ctx *matchContext) probability(status *MatchStatus) *models.MatchProbability {
+	if status.MatchComplete {
+		switch {
+		case status.WinnerTeamID == nil:
+			return &models.MatchProbability{Halve: 1}
+		case *status.WinnerTeamID == ctx.match.Team1ID:
+			return &models.MatchProbability{Team1Win: 1}
+		default:
+			return &models.MatchProbability{Team2Win: 1}
+		}
+	}
+
+	if status.InPlayoff {
+		next := ctx.firstPlayoffHole() + len(status.PlayoffHoles)
+		p1, p2 := ctx.holeOdds(ctx.playoffCourseHole(next))
+		return &models.MatchProbability{Team1Win: p1 / (p1 + p2), Team2Win: p2 / (p1 + p2)}
+	}
+
+	played := make(map[int]bool, len(status.HoleResults))
+	for _, result := range status.HoleResults {
+		played[result.HoleNumber] = true
+	}
+	var remaining []int
+	for _, holeNum := range ctx.holes {
+		if !played[holeNum] {
+			remaining = append(remaining, holeNum)
+		}
+	}
+
+	var probability *models.MatchProbability
+	if _, ok := ctx.scorer.(totalScorer); ok {
+		probability = ctx.totalProbability(status.Team1Up, remaining)
+	} else {
+		probability = ctx.holesProbability(status.Team1Up, remaining)
+	}
+
+	// A match that can't be halved goes to a playoff, won in proportion to the sides' hole odds
+	if ctx.match.MustProduceWinner && probability.Halve > 0 {
+		p1, p2 := ctx.holeOdds(ctx.holes[0])
+		probability.Team1Win += probability.Halve * p1 / (p1 + p2)
+		probability.Team2Win += probability.Halve * p2 / (p1 + p2)
+		probability.Halve = 0
+	}
+
+	return roundProbability(probability)
+}
+
+// holesProbability plays out the remaining holes exactly, tracking the chance of every margin.
+// Formats with several contests per hole play each contest as its own trial.
+func (ctx *matchContext) holesProbability(team1Up int, remaining []int) *models.MatchProbability {
+	margins := map[int]float64{team1Up: 1}
+	for _, holeNum := range remaining {
+		p1, p2 := ctx.holeOdds(holeNum)
+		for i := 0; i < ctx.swing(); i++ {
+			next := make(map[int]float64, len(margins)+2)
+			for margin, p := range margins {
+				next[margin+1] += p * p1
+				next[margin-1] += p * p2
+				next[margin] += p * (1 - p1 - p2)
+			}
+			margins = next
+		}
+	}
+
+	probability := &models.MatchProbability{}
+	for margin, p := range margins {
+		switch {
+		case margin > 0:
+			probability.Team1Win += p
+		case margin < 0:
+			probability.Team2Win += p
+		default:
+			probability.Halve += p
+		}
+	}
+	return probability
+}
+
+// totalProbability treats the final stroke or points margin as normally distributed around
+// the current margin plus team 1's expected advantage on each remaining hole
+func (ctx *matchContext) totalProbability(team1Up int, remaining []int) *models.MatchProbability {
+	mean := float64(team1Up)
+	for _, holeNum := range remaining {
+		mean += ctx.strokeAdvantage(holeNum)
+	}
+	sd := holeMarginSD * math.Sqrt(float64(len(remaining)))
+
+	if sd == 0 {
+		return ctx.holesProbability(team1Up, nil)
+	}
+	// Margins are whole numbers, so a halve is anything that rounds to zero
+	cdf := func(x float64) float64 { return 0.5 * (1 + math.Erf((x-mean)/(sd*math.Sqrt2))) }
+	return &models.MatchProbability{
+		Team1Win: 1 - cdf(0.5),
+		Halve:    cdf(0.5) - cdf(-0.5),
+		Team2Win: cdf(-0.5),
+	}
+}
+
+// holeOdds returns the chances of team 1 and team 2 winning a hole; the rest is a halve
+func (ctx *matchContext) holeOdds(holeNum int) (float64, float64) {
+	edge := math.Max(-maxHoleEdge, math.Min(maxHoleEdge, ctx.strokeAdvantage(holeNum)*holeStrokeEdge))
+	even := (1 - holeHalveRate) / 2
+	return even + edge, even - edge
+}
+
+// strokeAdvantage is team 1's expected edge on a hole in strokes: the gap between the sides'
+// average course handicaps spread over 18 holes, plus the difference in strokes received
+func (ctx *matchContext) strokeAdvantage(holeNum int) float64 {
+	strokes := ctx.allocation.forHole(holeNum)
+	side := func(team []string) (handicap, received float64) {
+		if len(team) == 0 {
+			return 0, 0
+		}
+		for _, userID := range team {
+			handicap += courseHandicap(ctx.roster.Handicaps[userID], ctx.tees[userID])
+			received += float64(strokes[userID])
+		}
+		return handicap / float64(len(team)), received / float64(len(team))
+	}
+
+	handicap1, received1 := side(ctx.roster.Team1)
+	handicap2, received2 := side(ctx.roster.Team2)
+	return (handicap2-handicap1)/18 + received1 - received2
+}
+
+func roundProbability(probability *models.MatchProbability) *models.MatchProbability {
+	round := func(p float64) float64 { return math.Round(p*10000) / 10000 }
+	probability.Team1Win = round(probability.Team1Win)
+	probability.Halve = round(probability.Halve)
+	probability.Team2Win = round(probability.Team2Win)
+	return probability
+}
+
+// cupProbabilities estimates each team's chance of taking the cup by playing out every
+// open match many times from its current win probabilities
+func (s *ScoringService) cupProbabilities(status *CupStatus, matches []models.Match) {
+	if status.Clinched {
+		for i := range status.Teams {
+			if status.WinnerTeamID != nil && *status.WinnerTeamID == status.Teams[i].TeamID {
+				status.Teams[i].WinProbability = 1
+			}
+		}
+		if status.Shared {
+			status.ShareProbability = 1
+		}
+		return
+	}
+
+	type openMatch struct {
+		team1, team2 int // indexes into status.Teams
+		points       float64
+		probability  *models.MatchProbability
+	}
+	side := make(map[string]int, len(status.Teams))
+	for i, team := range status.Teams {
+		side[team.TeamID] = i
+	}
+
+	var open []openMatch
+	for i := range matches {
+		match := &matches[i]
+		if match.Status == "completed" {
+			continue
+		}
+		probability := match.Probability
+		if probability == nil {
+			// Not scored yet, so use its starting odds
+			probability = s.startingOdds(match)
+		}
+		open = append(open, openMatch{side[match.Team1ID], side[match.Team2ID], match.PointsAvailable, probability})
+	}
+
+	// A fixed seed keeps the odds steady between requests until a result changes
+	rng := rand.New(rand.NewSource(1))
+	wins := make([]int, len(status.Teams))
+	shares := 0
+	final := &CupStatus{TargetPoints: status.TargetPoints, DefendingTeamID: status.DefendingTeamID}
+	for n := 0; n < cupSimulations; n++ {
+		final.Teams = append(final.Teams[:0], status.Teams...)
+		for _, match := range open {
+			switch r := rng.Float64(); {
+			case r < match.probability.Team1Win:
+				final.Teams[match.team1].Points += match.points
+			case r < match.probability.Team1Win+match.probability.Halve:
+				final.Teams[match.team1].Points += match.points / 2
+				final.Teams[match.team2].Points += match.points / 2
+			default:
+				final.Teams[match.team2].Points += match.points
+			}
+		}
+		for i := range final.Teams {
+			final.Teams[i].MaxPoints = final.Teams[i].Points
+		}
+
+		final.WinnerTeamID, final.Shared = nil, false
+		decideCup(final)
+		if final.WinnerTeamID == nil {
+			shares++
+			continue
+		}
+		wins[side[*final.WinnerTeamID]]++
+	}
+
+	for i := range status.Teams {
+		status.Teams[i].WinProbability = float64(wins[i]) / cupSimulations
+	}
+	status.ShareProbability = float64(shares) / cupSimulations
+}
@@ -0,0 +1,85 @@
+package scoring
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"mayhamapi/models"
+)
+
+func TestHolesProbability(t *testing.T) {
+	even := &MatchRoster{Team1: []string{"a1"}, Team2: []string{"b1"}}
+
+	tests := []struct {
+		name      string
+		scorer    FormatScorer
+		roster    *MatchRoster
+		team1Up   int
+		remaining int
+		want      models.MatchProbability
+	}{
+		{"won once no holes are left", matchPlayScorer{}, even, 1, 0, models.MatchProbability{Team1Win: 1}},
+		{"halved once no holes are left", matchPlayScorer{}, even, 0, 0, models.MatchProbability{Halve: 1}},
+		{"one hole left all square", matchPlayScorer{}, even, 0, 1, models.MatchProbability{Team1Win: 0.3, Halve: 0.4, Team2Win: 0.3}},
+		{"one hole left 1 up", matchPlayScorer{}, even, 1, 1, models.MatchProbability{Team1Win: 0.7, Halve: 0.3}},
+		{"dormie 2 up", matchPlayScorer{}, even, 2, 2, models.MatchProbability{Team1Win: 0.91, Halve: 0.09}},
+		{"dormie 2 down", matchPlayScorer{}, even, -2, 2, models.MatchProbability{Halve: 0.09, Team2Win: 0.91}},
+		{
+			"handicap gap capped per hole", matchPlayScorer{},
+			&MatchRoster{Team1: []string{"a1"}, Team2: []string{"b1"}, Handicaps: map[string]float64{"b1": 18}},
+			0, 1, models.MatchProbability{Team1Win: 0.55, Halve: 0.4, Team2Win: 0.05},
+		},
+		{
+			"handicap gap on a team's average", bestBallScorer{},
+			&MatchRoster{Team1: []string{"a1", "a2"}, Team2: []string{"b1", "b2"}, Handicaps: map[string]float64{"a1": 9, "a2": 9}},
+			0, 1, models.MatchProbability{Team1Win: 0.15, Halve: 0.4, Team2Win: 0.45},
+		},
+		{
+			"each split high-low contest is its own trial", highLowScorer{split: true},
+			&MatchRoster{Team1: []string{"a1", "a2"}, Team2: []string{"b1", "b2"}},
+			0, 1, models.MatchProbability{Team1Win: 0.33, Halve: 0.34, Team2Win: 0.33},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := testMatch(18)
+			ctx := &matchContext{match: match, scorer: tt.scorer, roster: tt.roster, holes: match.HolesOn(18)}
+
+			got := ctx.holesProbability(tt.team1Up, ctx.holes[:tt.remaining])
+
+			near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+			if !near(got.Team1Win, tt.want.Team1Win) || !near(got.Halve, tt.want.Halve) || !near(got.Team2Win, tt.want.Team2Win) {
+				t.Errorf("holesProbability = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvenOdds(t *testing.T) {
+	probability := evenOdds(testMatch(18))
+	if probability.Team1Win != probability.Team2Win {
+		t.Errorf("evenOdds = %+v, want the sides level", *probability)
+	}
+	if sum := probability.Team1Win + probability.Halve + probability.Team2Win; math.Abs(sum-1) > 0.001 {
+		t.Errorf("evenOdds adds up to %v, want 1", sum)
+	}
+}
+
+func TestOddsCacheEvictExpired(t *testing.T) {
+	now := time.Now()
+	cache := newOddsCache()
+	cache.entries["stale"] = cachedOdds{expires: now.Add(-time.Second)}
+	cache.entries["due"] = cachedOdds{expires: now}
+	cache.entries["fresh"] = cachedOdds{expires: now.Add(startingOddsTTL)}
+
+	cache.evictExpired(now)
+
+	if len(cache.entries) != 1 {
+		t.Errorf("cache holds %d entries after eviction, want 1", len(cache.entries))
+	}
+	if _, ok := cache.entries["fresh"]; !ok {
+		t.Error("the unexpired entry was evicted")
+	}
+}
@@ -0,0 +1,159 @@
+package scoring
+
+import (
+	"sort"
+)
+
+// RankingEntry is one player's line in a round's medal ranking
+type RankingEntry struct {
+	Rank        int    `json:"rank"`
+	UserID      string `json:"user_id"`
+	Name        string `json:"name"`
+	TeamID      string `json:"team_id"`
+	MatchID     string `json:"match_id"`
+	HolesPlayed int    `json:"holes_played"`
+	Gross       int    `json:"gross"`
+	Net         int    `json:"net"`
+	// ToPar and NetToPar cover the holes played so far; nil when the round has no course data
+	ToPar    *int `json:"to_par,omitempty"`
+	NetToPar *int `json:"net_to_par,omitempty"`
+	Points   *int `json:"points,omitempty"` // Stableford only
+}
+
+// RoundRankings ranks every player in a round's stroke play and Stableford matches
+type RoundRankings struct {
+	RoundID    string         `json:"round_id"`
+	StrokePlay []RankingEntry `json:"stroke_play"`
+	Stableford []RankingEntry `json:"stableford"`
+}
+
+// CalculateRoundRankings builds individual medal rankings from the stroke play and
+// Stableford matches in a round, alongside each match's head-to-head status
+func (s *ScoringService) CalculateRoundRankings(roundID string) (*RoundRankings, error) {
+	matches, err := s.repo.GetMatchesByRound(roundID)
+	if err != nil {
+		return nil, err
+	}
+
+	rankings := &RoundRankings{RoundID: roundID, StrokePlay: []RankingEntry{}, Stableford: []RankingEntry{}}
+	var userIDs []string
+	for i := range matches {
+		match := &matches[i]
+		ctx, err := s.loadMatchContext(match)
+		if err != nil {
+			return nil, err
+		}
+
+		stableford, isStableford := ctx.scorer.(stablefordScorer)
+		_, isStrokePlay := ctx.scorer.(strokePlayScorer)
+		if !isStableford && !isStrokePlay {
+			continue
+		}
+
+		scores, err := s.repo.GetMatchScores(match.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		entries := make(map[string]*RankingEntry)
+		for _, userID := range ctx.roster.players() {
+			teamID := match.Team1ID
+			if ctx.roster.side(userID) == 2 {
+				teamID = match.Team2ID
+			}
+			entry := &RankingEntry{UserID: userID, TeamID: teamID, MatchID: match.ID}
+			if len(ctx.course) > 0 {
+				entry.ToPar, entry.NetToPar = new(int), new(int)
+			}
+			if isStableford {
+				entry.Points = new(int)
+			}
+			entries[userID] = entry
+			userIDs = append(userIDs, userID)
+		}
+
+		inPlay := make(map[int]bool, len(ctx.holes))
+		for _, holeNum := range ctx.holes {
+			inPlay[holeNum] = true
+		}
+
+		for _, score := range scores {
+			entry, ok := entries[score.UserID]
+			if !ok || !inPlay[score.HoleNumber] {
+				continue
+			}
+			// Gross is the strokes actually taken; net comes from the capped score
+			net := score.Counted() - ctx.allocation.forHole(score.HoleNumber)[score.UserID]
+			entry.HolesPlayed++
+			entry.Gross += score.Strokes
+			entry.Net += net
+			if par := ctx.par(score.HoleNumber); par != nil && entry.ToPar != nil {
+				*entry.ToPar += score.Strokes - *par
+				*entry.NetToPar += net - *par
+				if isStableford {
+					*entry.Points += stablefordPoints(stableford.points, net-*par)
+				}
+			}
+		}
+
+		for _, userID := range ctx.roster.players() {
+			if isStableford {
+				rankings.Stableford = append(rankings.Stableford, *entries[userID])
+			} else {
+				rankings.StrokePlay = append(rankings.StrokePlay, *entries[userID])
+			}
+		}
+	}
+
+	if len(userIDs) > 0 {
+		users, err := s.repo.GetUsersByIDs(userIDs)
+		if err != nil {
+			return nil, err
+		}
+		names := make(map[string]string, len(users))
+		for _, user := range users {
+			names[user.ID] = user.Name
+		}
+		for i := range rankings.StrokePlay {
+			rankings.StrokePlay[i].Name = names[rankings.StrokePlay[i].UserID]
+		}
+		for i := range rankings.Stableford {
+			rankings.Stableford[i].Name = names[rankings.Stableford[i].UserID]
+		}
+	}
+
+	// Stroke play ranks on net relative to par when course data is available, so players
+	// who've completed different numbers of holes compare fairly
+	rankEntries(rankings.StrokePlay, func(e RankingEntry) int {
+		if e.NetToPar != nil {
+			return *e.NetToPar
+		}
+		return e.Net
+	})
+	rankEntries(rankings.Stableford, func(e RankingEntry) int {
+		if e.Points != nil {
+			return -*e.Points
+		}
+		return 0
+	})
+
+	return rankings, nil
+}
+
+// rankEntries sorts entries by ascending key and assigns ranks, with ties sharing a rank
+func rankEntries(entries []RankingEntry, key func(RankingEntry) int) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if key(entries[i]) != key(entries[j]) {
+			return key(entries[i]) < key(entries[j])
+		}
+		return entries[i].Gross < entries[j].Gross
+	})
+
+	for i := range entries {
+		if i > 0 && key(entries[i]) == key(entries[i-1]) {
+			entries[i].Rank = entries[i-1].Rank
+		} else {
+			entries[i].Rank = i + 1
+		}
+	}
+}
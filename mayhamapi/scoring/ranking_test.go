@@ -0,0 +1,68 @@
+package scoring
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRankEntries(t *testing.T) {
+	iptr := func(i int) *int { return &i }
+
+	tests := []struct {
+		name    string
+		entries []RankingEntry
+		key     func(RankingEntry) int
+		want    []string // user IDs, best first
+		ranks   []int
+	}{
+		{
+			name: "lowest net first",
+			entries: []RankingEntry{
+				{UserID: "a", Gross: 80, Net: 72},
+				{UserID: "b", Gross: 75, Net: 70},
+				{UserID: "c", Gross: 90, Net: 74},
+			},
+			key:   func(e RankingEntry) int { return e.Net },
+			want:  []string{"b", "a", "c"},
+			ranks: []int{1, 2, 3},
+		},
+		{
+			name: "ties share a rank and the next rank is skipped",
+			entries: []RankingEntry{
+				{UserID: "a", Gross: 80, Net: 72},
+				{UserID: "b", Gross: 76, Net: 72},
+				{UserID: "c", Gross: 90, Net: 74},
+			},
+			key:   func(e RankingEntry) int { return e.Net },
+			want:  []string{"b", "a", "c"}, // level on net, listed by gross
+			ranks: []int{1, 1, 3},
+		},
+		{
+			name: "most Stableford points first",
+			entries: []RankingEntry{
+				{UserID: "a", Gross: 85, Points: iptr(30)},
+				{UserID: "b", Gross: 88, Points: iptr(36)},
+				{UserID: "c", Gross: 84, Points: iptr(30)},
+			},
+			key:   func(e RankingEntry) int { return -*e.Points },
+			want:  []string{"b", "c", "a"},
+			ranks: []int{1, 2, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rankEntries(tt.entries, tt.key)
+
+			var got []string
+			var ranks []int
+			for _, e := range tt.entries {
+				got = append(got, e.UserID)
+				ranks = append(ranks, e.Rank)
+			}
+			if !reflect.DeepEqual(got, tt.want) || !reflect.DeepEqual(ranks, tt.ranks) {
+				t.Errorf("order %v ranks %v, want %v ranks %v", got, ranks, tt.want, tt.ranks)
+			}
+		})
+	}
+}
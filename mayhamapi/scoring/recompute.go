@@ -0,0 +1,235 @@
+package scoring
+
+import (
+	"mayhamapi/models"
+	"mayhamapi/repository"
+)
+
+// MatchResultSummary is the stored outcome of a match that a recompute can change
+type MatchResultSummary struct {
+	Status      string  `json:"status"`
+	ResultText  string  `json:"result_text"`
+	Team1Points float64 `json:"team1_points"`
+	Team2Points float64 `json:"team2_points"`
+}
+
+// MatchChange reports a match whose recomputed result differs from what's stored
+type MatchChange struct {
+	MatchID     string             `json:"match_id"`
+	RoundID     string             `json:"round_id"`
+	MatchNumber int                `json:"match_number"`
+	Before      MatchResultSummary `json:"before"`
+	After       MatchResultSummary `json:"after"`
+	// HolesChanged lists the holes whose stored hole_results row is added, removed or different
+	HolesChanged []int `json:"holes_changed"`
+	// ScoresReadjusted counts scores whose capped strokes change under the current handicaps and format
+	ScoresReadjusted int `json:"scores_readjusted,omitempty"`
+}
+
+// RecomputeError records a match that couldn't be rescored; the rest of the run carries on
+type RecomputeError struct {
+	MatchID string `json:"match_id"`
+	Error   string `json:"error"`
+}
+
+// RecomputeReport summarises a recompute run. Nothing is written when DryRun is set.
+type RecomputeReport struct {
+	TournamentID   *string          `json:"tournament_id,omitempty"`
+	DryRun         bool             `json:"dry_run"`
+	MatchesChecked int              `json:"matches_checked"`
+	Changes        []MatchChange    `json:"changes"`
+	Errors         []RecomputeError `json:"errors,omitempty"`
+}
+
+// Recompute rescores every match in a tournament, or every match when tournamentID is nil,
+// from its stored scores. Unless dryRun is set, all results and the affected tournaments'
+// player stats are rewritten in one transaction.
+func (s *ScoringService) Recompute(tournamentID *string, dryRun bool) (*RecomputeReport, error) {
+	var matches []models.Match
+	var err error
+	if tournamentID != nil {
+		if _, err := s.repo.GetTournament(*tournamentID); err != nil {
+			return nil, err
+		}
+		matches, err = s.tournamentMatches(*tournamentID)
+	} else {
+		matches, err = s.repo.ListMatches()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RecomputeReport{TournamentID: tournamentID, DryRun: dryRun, Changes: []MatchChange{}}
+	statuses := make(map[string]*MatchStatus, len(matches))
+	readjusted := make(map[string][]models.Score, len(matches))
+	for i := range matches {
+		match := &matches[i]
+		report.MatchesChecked++
+
+		status, scores, change, err := s.recomputeMatch(match)
+		if err != nil {
+			report.Errors = append(report.Errors, RecomputeError{MatchID: match.ID, Error: err.Error()})
+			continue
+		}
+		if status == nil {
+			continue
+		}
+		statuses[match.ID] = status
+		readjusted[match.ID] = scores
+		if change != nil {
+			report.Changes = append(report.Changes, *change)
+		}
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	err = s.repo.WithTx(func(tx *repository.Repository) error {
+		tournamentIDs := make(map[string]bool)
+		for i := range matches {
+			if status, ok := statuses[matches[i].ID]; ok {
+				for _, score := range readjusted[matches[i].ID] {
+					if err := tx.UpdateAdjustedStrokes(score.ID, *score.AdjustedStrokes); err != nil {
+						return err
+					}
+				}
+				if err := s.saveMatchResult(tx, &matches[i], status); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Player stats follow the rewritten results, once per tournament
+		for i := range matches {
+			round, err := tx.GetRound(matches[i].RoundID)
+			if err != nil {
+				return err
+			}
+			if tournamentIDs[round.TournamentID] {
+				continue
+			}
+			tournamentIDs[round.TournamentID] = true
+			if _, err := s.WithRepo(tx).refreshPlayerStats(round.TournamentID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// recomputeMatch rescores one match from its raw strokes and compares the result with
+// what's stored. Each score's caps are worked out again under the current handicaps and
+// format, and the scores whose adjusted strokes change are returned to be saved. The
+// change is nil when nothing would be different, and the status is also nil for a match
+// that has never been scored.
+func (s *ScoringService) recomputeMatch(match *models.Match) (*MatchStatus, []models.Score, *MatchChange, error) {
+	scores, err := s.repo.GetMatchScores(match.ID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	stored, err := s.repo.GetHoleResults(match.ID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(scores) == 0 && len(stored) == 0 && match.ResultText == nil {
+		return nil, nil, nil, nil
+	}
+
+	ctx, err := s.loadMatchContext(match)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var readjusted []models.Score
+	for i := range scores {
+		adjusted := ctx.adjustedStrokes(scores[i].UserID, scores[i].HoleNumber, scores[i].Strokes)
+		if scores[i].AdjustedStrokes == nil || *scores[i].AdjustedStrokes != adjusted {
+			scores[i].AdjustedStrokes = &adjusted
+			readjusted = append(readjusted, scores[i])
+		}
+	}
+
+	status, err := s.CalculateMatchStatus(match, scores)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	change := &MatchChange{
+		MatchID:     match.ID,
+		RoundID:     match.RoundID,
+		MatchNumber: match.MatchNumber,
+		Before: MatchResultSummary{
+			Status:      match.Status,
+			Team1Points: match.Team1Points,
+			Team2Points: match.Team2Points,
+		},
+		After: MatchResultSummary{
+			Status:      matchState(match, status),
+			ResultText:  status.ResultText,
+			Team1Points: status.Team1MatchPoints,
+			Team2Points: status.Team2MatchPoints,
+		},
+		HolesChanged:     changedHoles(stored, holeResultRecords(match, status)),
+		ScoresReadjusted: len(readjusted),
+	}
+	if match.ResultText != nil {
+		change.Before.ResultText = *match.ResultText
+	}
+
+	if change.Before == change.After && len(change.HolesChanged) == 0 && len(readjusted) == 0 {
+		return status, readjusted, nil, nil
+	}
+	return status, readjusted, change, nil
+}
+
+// changedHoles returns the hole numbers that differ between two sets of hole results
+func changedHoles(before, after []models.HoleResult) []int {
+	byHole := make(map[int]models.HoleResult, len(before))
+	for _, result := range before {
+		byHole[result.HoleNumber] = result
+	}
+
+	changed := []int{}
+	for _, result := range after {
+		old, ok := byHole[result.HoleNumber]
+		if !ok || !sameHoleResult(old, result) {
+			changed = append(changed, result.HoleNumber)
+		}
+		delete(byHole, result.HoleNumber)
+	}
+	for _, result := range before {
+		if _, removed := byHole[result.HoleNumber]; removed {
+			changed = append(changed, result.HoleNumber)
+		}
+	}
+	return changed
+}
+
+func sameHoleResult(a, b models.HoleResult) bool {
+	if !sameInt(a.Team1Score, b.Team1Score) || !sameInt(a.Team2Score, b.Team2Score) ||
+		!sameString(a.WinnerTeamID, b.WinnerTeamID) || a.Team1Points != b.Team1Points ||
+		a.Team2Points != b.Team2Points || len(a.Contests) != len(b.Contests) {
+		return false
+	}
+	for i := range a.Contests {
+		x, y := a.Contests[i], b.Contests[i]
+		if x.Name != y.Name || x.Team1Score != y.Team1Score || x.Team2Score != y.Team2Score ||
+			!sameString(x.WinnerTeamID, y.WinnerTeamID) || x.Team1Points != y.Team1Points || x.Team2Points != y.Team2Points {
+			return false
+		}
+	}
+	return true
+}
+
+func sameInt(a, b *int) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+func sameString(a, b *string) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
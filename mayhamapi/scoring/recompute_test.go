@@ -0,0 +1,61 @@
+package scoring
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"mayhamapi/models"
+)
+
+func TestChangedHoles(t *testing.T) {
+	t1, t2 := "t1", "t2"
+	// won is a hole t1 won 4-5, as first saved
+	won := func(holeNum int) models.HoleResult {
+		team1, team2 := 4, 5
+		return models.HoleResult{ID: "r", HoleNumber: holeNum, Team1Score: &team1, Team2Score: &team2, WinnerTeamID: &t1, Team1Points: 1}
+	}
+	halved := func(holeNum int) models.HoleResult {
+		score := 4
+		return models.HoleResult{HoleNumber: holeNum, Team1Score: &score, Team2Score: &score, Team1Points: 0.5, Team2Points: 0.5}
+	}
+	split := func(holeNum int, highWinner *string) models.HoleResult {
+		result := won(holeNum)
+		result.Contests = models.HoleContests{
+			{Name: "low", Team1Score: 3, Team2Score: 4, WinnerTeamID: &t1, Team1Points: 1},
+			{Name: "high", Team1Score: 5, Team2Score: 5, WinnerTeamID: highWinner, Team1Points: 0.5, Team2Points: 0.5},
+		}
+		return result
+	}
+	resaved := won(1)
+	resaved.ID, resaved.CreatedAt, resaved.UpdatedAt = "", time.Now(), time.Now()
+	rescored := won(2)
+	five := 5
+	rescored.Team1Score = &five
+	flipped := won(2)
+	flipped.WinnerTeamID, flipped.Team1Points, flipped.Team2Points = &t2, 0, 1
+
+	tests := []struct {
+		name          string
+		before, after []models.HoleResult
+		want          []int
+	}{
+		{"nothing changed", []models.HoleResult{won(1), halved(2)}, []models.HoleResult{won(1), halved(2)}, []int{}},
+		{"IDs and timestamps don't count", []models.HoleResult{won(1)}, []models.HoleResult{resaved}, []int{}},
+		{"new hole", []models.HoleResult{won(1)}, []models.HoleResult{won(1), halved(2)}, []int{2}},
+		{"hole removed", []models.HoleResult{won(1), halved(2), won(3)}, []models.HoleResult{won(1)}, []int{2, 3}},
+		{"different winner", []models.HoleResult{won(1), won(2)}, []models.HoleResult{won(1), flipped}, []int{2}},
+		{"same winner with a different score", []models.HoleResult{won(1), won(2)}, []models.HoleResult{won(1), rescored}, []int{2}},
+		{"contest changed", []models.HoleResult{split(1, nil)}, []models.HoleResult{split(1, &t1)}, []int{1}},
+		{"contests added", []models.HoleResult{won(1)}, []models.HoleResult{split(1, nil)}, []int{1}},
+		{"changed, added and removed", []models.HoleResult{won(1), won(2), won(3)}, []models.HoleResult{halved(1), won(2), won(4)}, []int{1, 4, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := changedHoles(tt.before, tt.after); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changedHoles = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package scoring
+
+import (
+	"fmt"
+	"math"
+
+	"mayhamapi/models"
+	"mayhamapi/repository"
+)
+
+// matchPoints splits the match's points_available once it is complete: all to the
+// winner, or half each for a halved match. A Nassau's front, back, overall and press
+// bets are separate wagers, so its points are shared in proportion to the bets each
+// side won. A conceded Nassau, or one level on bets that went to a playoff, goes
+// wholly to the winner.
+func matchPoints(match *models.Match, status *MatchStatus) (float64, float64) {
+	if !status.MatchComplete {
+		return 0, 0
+	}
+
+	bets := status.Team1SegmentPoints + status.Team2SegmentPoints
+	if bets > 0 && status.ConcededByTeamID == nil && !status.InPlayoff {
+		team1 := math.Round(match.PointsAvailable*status.Team1SegmentPoints/bets*100) / 100
+		return team1, math.Round((match.PointsAvailable-team1)*100) / 100
+	}
+
+	switch {
+	case status.WinnerTeamID == nil:
+		return match.PointsAvailable / 2, match.PointsAvailable / 2
+	case *status.WinnerTeamID == match.Team1ID:
+		return match.PointsAvailable, 0
+	default:
+		return 0, match.PointsAvailable
+	}
+}
+
+// checkMatchPoints guards against a result that hands out more points than the match is worth
+func checkMatchPoints(match *models.Match, status *MatchStatus) error {
+	team1, team2 := status.Team1MatchPoints, status.Team2MatchPoints
+	if team1 < 0 || team2 < 0 || team1+team2 > match.PointsAvailable+1e-9 {
+		return fmt.Errorf("match result %g-%g doesn't fit within the %g points available", team1, team2, match.PointsAvailable)
+	}
+	return nil
+}
+
+// matchState maps a calculated status onto the matches.status column
+func matchState(match *models.Match, status *MatchStatus) string {
+	switch {
+	case status.MatchComplete:
+		return "completed"
+	case status.HolesCompleted > 0:
+		return "in_progress"
+	default:
+		return match.Status
+	}
+}
+
+// SaveMatchResult writes the per-hole results, team points, result text, status, progress and win probabilities for a match,
+// and brings its players' stats up to date. Pass a transaction-bound repository to save alongside the
+// score writes that produced the status.
+func (s *ScoringService) SaveMatchResult(repo *repository.Repository, match *models.Match, status *MatchStatus) error {
+	return repo.WithTx(func(tx *repository.Repository) error {
+		if err := s.saveMatchResult(tx, match, status); err != nil {
+			return err
+		}
+		round, err := tx.GetRound(match.RoundID)
+		if err != nil {
+			return err
+		}
+		return s.WithRepo(tx).refreshMatchPlayerStats(round.TournamentID, match)
+	})
+}
+
+// saveMatchResult is SaveMatchResult without the player stats, for callers saving many matches at once
+func (s *ScoringService) saveMatchResult(repo *repository.Repository, match *models.Match, status *MatchStatus) error {
+	if err := checkMatchPoints(match, status); err != nil {
+		return err
+	}
+	records := holeResultRecords(match, status)
+
+	return repo.WithTx(func(tx *repository.Repository) error {
+		if err := tx.SaveHoleResults(match.ID, records); err != nil {
+			return err
+		}
+		if err := tx.UpdateMatchResult(match.ID, matchState(match, status), status.Team1MatchPoints, status.Team2MatchPoints, status.ResultText); err != nil {
+			return err
+		}
+		if err := tx.UpdateMatchProbability(match.ID, status.Probability); err != nil {
+			return err
+		}
+		return tx.UpdateMatchProgress(match.ID, status.HolesCompleted, status.Team1Up, status.ScoringUnit)
+	})
+}
+
+// holeResultRecords converts the status's regulation and playoff holes into hole_results rows
+func holeResultRecords(match *models.Match, status *MatchStatus) []models.HoleResult {
+	records := make([]models.HoleResult, 0, len(status.HoleResults)+len(status.PlayoffHoles))
+	for _, result := range append(append([]HoleResult{}, status.HoleResults...), status.PlayoffHoles...) {
+		records = append(records, models.HoleResult{
+			MatchID:      match.ID,
+			HoleNumber:   result.HoleNumber,
+			Team1Score:   result.Team1Score,
+			Team2Score:   result.Team2Score,
+			WinnerTeamID: result.WinnerTeamID,
+			Team1Points:  result.Team1Points,
+			Team2Points:  result.Team2Points,
+			Contests:     result.Contests,
+		})
+	}
+	return records
+}
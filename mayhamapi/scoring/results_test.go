@@ -0,0 +1,106 @@
+package scoring
+
+import (
+	"testing"
+
+	"mayhamapi/models"
+)
+
+func TestMatchPoints(t *testing.T) {
+	t1, t2 := "t1", "t2"
+
+	tests := []struct {
+		name                     string
+		status                   MatchStatus
+		team1Points, team2Points float64
+	}{
+		{"in progress", MatchStatus{WinnerTeamID: &t1}, 0, 0},
+		{"won", MatchStatus{MatchComplete: true, WinnerTeamID: &t1}, 2, 0},
+		{"lost", MatchStatus{MatchComplete: true, WinnerTeamID: &t2}, 0, 2},
+		{"halved", MatchStatus{MatchComplete: true}, 1, 1},
+		{"conceded", MatchStatus{MatchComplete: true, WinnerTeamID: &t2, ConcededByTeamID: &t1}, 0, 2},
+		{
+			"Nassau shared by bets won",
+			MatchStatus{MatchComplete: true, WinnerTeamID: &t1, Team1SegmentPoints: 2, Team2SegmentPoints: 1},
+			1.33, 0.67,
+		},
+		{
+			"Nassau with presses",
+			MatchStatus{MatchComplete: true, WinnerTeamID: &t2, Team1SegmentPoints: 1.5, Team2SegmentPoints: 2.5},
+			0.75, 1.25,
+		},
+		{
+			"Nassau halved on bets",
+			MatchStatus{MatchComplete: true, Team1SegmentPoints: 1.5, Team2SegmentPoints: 1.5},
+			1, 1,
+		},
+		{
+			"conceded Nassau goes to the winner",
+			MatchStatus{MatchComplete: true, WinnerTeamID: &t1, ConcededByTeamID: &t2, Team1SegmentPoints: 0.5, Team2SegmentPoints: 1.5},
+			2, 0,
+		},
+		{
+			"Nassau decided in a playoff goes to the winner",
+			MatchStatus{MatchComplete: true, InPlayoff: true, WinnerTeamID: &t2, Team1SegmentPoints: 1.5, Team2SegmentPoints: 1.5},
+			0, 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := &models.Match{Team1ID: t1, Team2ID: t2, PointsAvailable: 2}
+			team1, team2 := matchPoints(match, &tt.status)
+			if team1 != tt.team1Points || team2 != tt.team2Points {
+				t.Errorf("matchPoints = %v-%v, want %v-%v", team1, team2, tt.team1Points, tt.team2Points)
+			}
+		})
+	}
+}
+
+func TestMatchState(t *testing.T) {
+	tests := []struct {
+		name   string
+		stored string
+		status MatchStatus
+		want   string
+	}{
+		{"complete", "in_progress", MatchStatus{MatchComplete: true, HolesCompleted: 15}, "completed"},
+		{"first hole scored", "scheduled", MatchStatus{HolesCompleted: 1}, "in_progress"},
+		{"no holes scored keeps the stored status", "scheduled", MatchStatus{}, "scheduled"},
+		{"scores removed from a completed match", "in_progress", MatchStatus{HolesCompleted: 17}, "in_progress"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := &models.Match{Status: tt.stored}
+			if got := matchState(match, &tt.status); got != tt.want {
+				t.Errorf("matchState = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckMatchPoints(t *testing.T) {
+	tests := []struct {
+		name                     string
+		team1Points, team2Points float64
+		wantErr                  bool
+	}{
+		{"all to one side", 1, 0, false},
+		{"halved", 0.5, 0.5, false},
+		{"not yet decided", 0, 0, false},
+		{"Nassau split", 0.67, 0.33, false},
+		{"more than available", 1, 0.5, true},
+		{"negative", -0.5, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := &models.Match{PointsAvailable: 1}
+			status := &MatchStatus{Team1MatchPoints: tt.team1Points, Team2MatchPoints: tt.team2Points}
+			if err := checkMatchPoints(match, status); (err != nil) != tt.wantErr {
+				t.Errorf("checkMatchPoints error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package scoring
+
+import (
+	"errors"
+	"fmt"
+
+	"mayhamapi/models"
+)
+
+// MatchRoster lists the players on each side of a match
+type MatchRoster struct {
+	Team1     []string           `json:"team1"`
+	Team2     []string           `json:"team2"`
+	Handicaps map[string]float64 `json:"handicaps"` // handicap index by user ID, 0 when not set
+	Tees      map[string]string  `json:"tees"`      // per-player tee overrides by user ID
+}
+
+// players returns every rostered user ID, team 1 first
+func (r *MatchRoster) players() []string {
+	players := make([]string, 0, len(r.Team1)+len(r.Team2))
+	players = append(players, r.Team1...)
+	return append(players, r.Team2...)
+}
+
+// side returns 1 or 2 for a rostered player, or 0 if the player is not in the match
+func (r *MatchRoster) side(userID string) int {
+	for _, id := range r.Team1 {
+		if id == userID {
+			return 1
+		}
+	}
+	for _, id := range r.Team2 {
+		if id == userID {
+			return 2
+		}
+	}
+	return 0
+}
+
+// holeComplete reports whether enough scores have been posted to decide the hole.
+// Team-ball formats need one score per side; every other format needs every rostered player.
+func (r *MatchRoster) holeComplete(scorer FormatScorer, hole *HoleInput) bool {
+	if _, ok := scorer.(teamBallScorer); ok {
+		return len(hole.Team1) > 0 && len(hole.Team2) > 0
+	}
+	return len(hole.Team1) == len(r.Team1) && len(hole.Team2) == len(r.Team2)
+}
+
+// errNoRoster is returned by loadRoster for a match that doesn't have players on both sides yet
+var errNoRoster = errors.New("has no rostered players for one or both teams")
+
+// loadRoster resolves the match's players from match_players, falling back to
+// the full team_members lists when no players were assigned to the match.
+func (s *ScoringService) loadRoster(match *models.Match) (*MatchRoster, error) {
+	players, err := s.repo.GetMatchPlayers(match.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	roster := &MatchRoster{Tees: make(map[string]string)}
+	for _, player := range players {
+		if player.TeeID != nil {
+			roster.Tees[player.UserID] = *player.TeeID
+		}
+		switch player.TeamID {
+		case match.Team1ID:
+			roster.Team1 = append(roster.Team1, player.UserID)
+		case match.Team2ID:
+			roster.Team2 = append(roster.Team2, player.UserID)
+		}
+	}
+
+	if len(players) == 0 {
+		team1Members, err := s.repo.GetTeamMembers(match.Team1ID)
+		if err != nil {
+			return nil, err
+		}
+		team2Members, err := s.repo.GetTeamMembers(match.Team2ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range team1Members {
+			roster.Team1 = append(roster.Team1, member.UserID)
+		}
+		for _, member := range team2Members {
+			roster.Team2 = append(roster.Team2, member.UserID)
+		}
+	}
+
+	if len(roster.Team1) == 0 || len(roster.Team2) == 0 {
+		return nil, fmt.Errorf("match %s %w", match.ID, errNoRoster)
+	}
+
+	users, err := s.repo.GetUsersByIDs(roster.players())
+	if err != nil {
+		return nil, err
+	}
+	roster.Handicaps = make(map[string]float64, len(users))
+	for _, user := range users {
+		if user.Handicap != nil {
+			roster.Handicaps[user.ID] = *user.Handicap
+		}
+	}
+
+	return roster, nil
+}
@@ -0,0 +1,59 @@
+package scoring
+
+import (
+	"testing"
+
+	"mayhamapi/models"
+)
+
+func TestSplitHoleScores(t *testing.T) {
+	roster := &MatchRoster{Team1: []string{"z1", "z2"}, Team2: []string{"a1", "a2"}}
+	// Scores come back ordered by user ID, which says nothing about their teams
+	scores := []models.Score{
+		{UserID: "a1", Strokes: 4}, {UserID: "a2", Strokes: 5}, {UserID: "x9", Strokes: 3},
+		{UserID: "z1", Strokes: 6}, {UserID: "z2", Strokes: 7},
+	}
+
+	hole := splitHoleScores(testMatch(18), roster, 1, scores)
+
+	side := func(scores []models.Score) map[string]int {
+		strokes := make(map[string]int)
+		for _, score := range scores {
+			strokes[score.UserID] = score.Strokes
+		}
+		return strokes
+	}
+	team1, team2 := side(hole.Team1), side(hole.Team2)
+	if len(team1) != 2 || team1["z1"] != 6 || team1["z2"] != 7 {
+		t.Errorf("team 1 scores = %v, want z1 and z2", team1)
+	}
+	if len(team2) != 2 || team2["a1"] != 4 || team2["a2"] != 5 {
+		t.Errorf("team 2 scores = %v, want a1 and a2", team2)
+	}
+}
+
+func TestHoleComplete(t *testing.T) {
+	roster := &MatchRoster{Team1: []string{"a1", "a2"}, Team2: []string{"b1", "b2"}}
+
+	tests := []struct {
+		name         string
+		scorer       FormatScorer
+		team1, team2 map[string]int
+		want         bool
+	}{
+		{"every rostered player posted", bestBallScorer{}, map[string]int{"a1": 4, "a2": 5}, map[string]int{"b1": 4, "b2": 6}, true},
+		{"one player still to post", bestBallScorer{}, map[string]int{"a1": 4, "a2": 5}, map[string]int{"b1": 4}, false},
+		{"one score a side isn't enough for individual balls", highLowScorer{}, map[string]int{"a1": 4}, map[string]int{"b1": 4}, false},
+		{"team ball needs one score a side", scrambleScorer{}, map[string]int{"a1": 4}, map[string]int{"b2": 4}, true},
+		{"team ball side still to post", alternateShotScorer{}, map[string]int{"a1": 4}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hole := &HoleInput{Match: testMatch(18), HoleNumber: 1, Team1: postScores(1, tt.team1), Team2: postScores(1, tt.team2)}
+			if got := roster.holeComplete(tt.scorer, hole); got != tt.want {
+				t.Errorf("holeComplete = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package scoring
+
+import (
+	"mayhamapi/models"
+)
+
+// Scorecard is a match laid out hole by hole, the way it would be printed
+type Scorecard struct {
+	MatchID       string             `json:"match_id"`
+	MatchNumber   int                `json:"match_number"`
+	Format        models.MatchFormat `json:"format"`
+	HandicapMode  string             `json:"handicap_mode"`
+	ScoringUnit   string             `json:"scoring_unit"` // what Team1Up counts: holes, strokes or points
+	Team1         ScorecardTeam      `json:"team1"`
+	Team2         ScorecardTeam      `json:"team2"`
+	Holes         []ScorecardHole    `json:"holes"`     // regulation holes in playing order, then any playoff holes
+	Subtotals     []ScorecardTotal   `json:"subtotals"` // Out (holes 1-9) and In (10-18) when the match plays both nines, then Total
+	ResultText    string             `json:"result_text"`
+	MatchComplete bool               `json:"match_complete"`
+	// ConcededByTeamID is set when the match itself was conceded
+	ConcededByTeamID *string `json:"conceded_by_team_id,omitempty"`
+}
+
+// ScorecardTeam is one side of the match and its players, in roster order
+type ScorecardTeam struct {
+	TeamID  string            `json:"team_id"`
+	Name    string            `json:"name"`
+	Players []ScorecardPlayer `json:"players"`
+}
+
+type ScorecardPlayer struct {
+	UserID          string `json:"user_id"`
+	Name            string `json:"name"`
+	PlayingHandicap *int   `json:"playing_handicap,omitempty"` // nil for gross matches
+}
+
+// ScorecardHole is one column of the card. Playoff holes keep their playoff number and
+// take par and stroke index from the course hole being replayed.
+type ScorecardHole struct {
+	HoleNumber  int              `json:"hole_number"`
+	Playoff     bool             `json:"playoff,omitempty"`
+	Par         *int             `json:"par,omitempty"`
+	StrokeIndex *int             `json:"stroke_index,omitempty"`
+	Scores      []ScorecardScore `json:"scores"` // one per player, team 1 first
+	// Team scores, winner and running margin are only set once the hole is decided
+	Team1Score   *int    `json:"team1_score,omitempty"`
+	Team2Score   *int    `json:"team2_score,omitempty"`
+	WinnerTeamID *string `json:"winner_team_id,omitempty"`
+	Team1Up      *int    `json:"team1_up,omitempty"`
+	// ConcededByTeamID is set when the hole was conceded; Concessions lists the hole and putt concessions on it
+	ConcededByTeamID *string             `json:"conceded_by_team_id,omitempty"`
+	Concessions      []models.Concession `json:"concessions,omitempty"`
+}
+
+// ScorecardScore is one player's box on a hole. Strokes is the number of handicap dots.
+// Conceded is set when the score counts a conceded putt.
+type ScorecardScore struct {
+	UserID   string `json:"user_id"`
+	Gross    *int   `json:"gross"`
+	Net      *int   `json:"net"`
+	Strokes  int    `json:"strokes"`
+	Conceded bool   `json:"conceded,omitempty"`
+}
+
+// ScorecardTotal adds up a run of regulation holes
+type ScorecardTotal struct {
+	Label       string               `json:"label"` // Out, In or Total
+	Par         *int                 `json:"par,omitempty"`
+	Players     []ScorecardPlayerSum `json:"players"`
+	Team1Points float64              `json:"team1_points"`
+	Team2Points float64              `json:"team2_points"`
+}
+
+type ScorecardPlayerSum struct {
+	UserID string `json:"user_id"`
+	Gross  int    `json:"gross"`
+	Net    int    `json:"net"`
+}
+
+// BuildScorecard lays out a match's scores and results hole by hole, with subtotals.
+// Scores count conceded putts, as the match result does.
+func (s *ScoringService) BuildScorecard(match *models.Match) (*Scorecard, error) {
+	ctx, err := s.loadMatchContext(match)
+	if err != nil {
+		return nil, err
+	}
+
+	scores, err := s.repo.GetMatchScores(match.ID)
+	if err != nil {
+		return nil, err
+	}
+	concessions, err := s.repo.GetMatchConcessions(match.ID)
+	if err != nil {
+		return nil, err
+	}
+	status, err := s.CalculateMatchStatus(match, scores)
+	if err != nil {
+		return nil, err
+	}
+
+	round, err := s.repo.GetRound(match.RoundID)
+	if err != nil {
+		return nil, err
+	}
+	teams, err := s.repo.GetTeamsByTournament(round.TournamentID)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.repo.GetUsersByIDs(ctx.roster.players())
+	if err != nil {
+		return nil, err
+	}
+
+	teamNames := make(map[string]string, len(teams))
+	for _, team := range teams {
+		teamNames[team.ID] = team.Name
+	}
+	userNames := make(map[string]string, len(users))
+	for _, user := range users {
+		userNames[user.ID] = user.Name
+	}
+	side := func(teamID string, userIDs []string) ScorecardTeam {
+		team := ScorecardTeam{TeamID: teamID, Name: teamNames[teamID]}
+		for _, userID := range userIDs {
+			player := ScorecardPlayer{UserID: userID, Name: userNames[userID]}
+			if handicap, ok := ctx.playing[userID]; ok {
+				player.PlayingHandicap = &handicap
+			}
+			team.Players = append(team.Players, player)
+		}
+		return team
+	}
+
+	card := &Scorecard{
+		MatchID:          match.ID,
+		MatchNumber:      match.MatchNumber,
+		Format:           ctx.format,
+		HandicapMode:     match.HandicapMode,
+		ScoringUnit:      status.ScoringUnit,
+		Team1:            side(match.Team1ID, ctx.roster.Team1),
+		Team2:            side(match.Team2ID, ctx.roster.Team2),
+		ResultText:       status.ResultText,
+		MatchComplete:    status.MatchComplete,
+		ConcededByTeamID: status.ConcededByTeamID,
+	}
+
+	card.Holes, card.Subtotals = ctx.scorecardHoles(scores, concessions, status)
+	return card, nil
+}
+
+// scorecardHoles lays out the card's holes and subtotals from the match's scores and
+// concessions and the status calculated from them
+func (ctx *matchContext) scorecardHoles(scores []models.Score, concessions []models.Concession, status *MatchStatus) ([]ScorecardHole, []ScorecardTotal) {
+	// Conceded putts replace the submitted strokes, the same way the match is scored
+	holeScores := make(map[int][]models.Score)
+	for _, score := range scores {
+		holeScores[score.HoleNumber] = append(holeScores[score.HoleNumber], score)
+	}
+	ctx.applyConcessions(concessions, holeScores)
+
+	results := make(map[int]HoleResult, len(status.HoleResults)+len(status.PlayoffHoles))
+	for _, result := range status.HoleResults {
+		results[result.HoleNumber] = result
+	}
+	// A playoff hole's margin is the match's: level until a hole is won, then 1 up
+	for _, result := range status.PlayoffHoles {
+		result.Team1Up = 0
+		if result.WinnerTeamID != nil {
+			result.Team1Up = 1
+			if *result.WinnerTeamID == ctx.match.Team2ID {
+				result.Team1Up = -1
+			}
+		}
+		results[result.HoleNumber] = result
+	}
+
+	hole := func(holeNum, courseHoleNum int) ScorecardHole {
+		hole := ScorecardHole{HoleNumber: holeNum, Par: ctx.par(courseHoleNum)}
+		if courseHole, ok := ctx.course[courseHoleNum]; ok {
+			strokeIndex := courseHole.StrokeIndex
+			hole.StrokeIndex = &strokeIndex
+		}
+
+		byPlayer := make(map[string]models.Score, len(holeScores[holeNum]))
+		for _, score := range holeScores[holeNum] {
+			byPlayer[score.UserID] = score
+		}
+		concededPutts := make(map[string]bool)
+		for _, concession := range ctx.holeConcessions[holeNum] {
+			if concession.Type == models.ConcedePutt && concession.UserID != nil && concession.Strokes != nil {
+				concededPutts[*concession.UserID] = true
+			}
+		}
+
+		strokes := ctx.allocation.forHole(courseHoleNum)
+		for _, userID := range ctx.roster.players() {
+			box := ScorecardScore{UserID: userID, Strokes: strokes[userID], Conceded: concededPutts[userID]}
+			if score, ok := byPlayer[userID]; ok {
+				gross := score.Strokes
+				net := score.Counted() - box.Strokes
+				box.Gross, box.Net = &gross, &net
+			}
+			hole.Scores = append(hole.Scores, box)
+		}
+
+		if result, ok := results[holeNum]; ok {
+			team1Up := result.Team1Up
+			hole.Team1Score, hole.Team2Score = result.Team1Score, result.Team2Score
+			hole.WinnerTeamID = result.WinnerTeamID
+			hole.Team1Up = &team1Up
+			hole.ConcededByTeamID = result.ConcededByTeamID
+		}
+		hole.Concessions = ctx.holeConcessions[holeNum]
+		return hole
+	}
+
+	var holes, out, in []ScorecardHole
+	var subtotals []ScorecardTotal
+	for _, holeNum := range ctx.holes {
+		holes = append(holes, hole(holeNum, holeNum))
+		if holeNum <= 9 {
+			out = append(out, holes[len(holes)-1])
+		} else {
+			in = append(in, holes[len(holes)-1])
+		}
+	}
+	if len(out) > 0 && len(in) > 0 {
+		subtotals = append(subtotals,
+			scorecardTotal("Out", out, results),
+			scorecardTotal("In", in, results),
+		)
+	}
+	subtotals = append(subtotals, scorecardTotal("Total", holes, results))
+
+	// Playoff holes follow the total, including one still being played
+	for holeNum := ctx.firstPlayoffHole(); status.InPlayoff && (len(holeScores[holeNum]) > 0 || ctx.concededHoles[holeNum] != ""); holeNum++ {
+		playoff := hole(holeNum, ctx.playoffCourseHole(holeNum))
+		playoff.Playoff = true
+		holes = append(holes, playoff)
+	}
+
+	return holes, subtotals
+}
+
+// scorecardTotal adds up par, each player's gross and net, and the hole points over a run of holes.
+// Par is only given when every hole in the run has one.
+func scorecardTotal(label string, holes []ScorecardHole, results map[int]HoleResult) ScorecardTotal {
+	total := ScorecardTotal{Label: label}
+	par, parKnown := 0, true
+	index := make(map[string]int)
+	for _, hole := range holes {
+		if hole.Par != nil {
+			par += *hole.Par
+		} else {
+			parKnown = false
+		}
+
+		for _, box := range hole.Scores {
+			i, ok := index[box.UserID]
+			if !ok {
+				i = len(total.Players)
+				index[box.UserID] = i
+				total.Players = append(total.Players, ScorecardPlayerSum{UserID: box.UserID})
+			}
+			if box.Gross != nil {
+				total.Players[i].Gross += *box.Gross
+				total.Players[i].Net += *box.Net
+			}
+		}
+
+		if result, ok := results[hole.HoleNumber]; ok {
+			total.Team1Points += result.Team1Points
+			total.Team2Points += result.Team2Points
+		}
+	}
+	if parKnown && len(holes) > 0 {
+		total.Par = &par
+	}
+	return total
+}
@@ -0,0 +1,144 @@
+package scoring
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"mayhamapi/models"
+)
+
+func TestScorecardTotal(t *testing.T) {
+	iptr := func(i int) *int { return &i }
+	box := func(userID string, gross, net int) ScorecardScore {
+		return ScorecardScore{UserID: userID, Gross: iptr(gross), Net: iptr(net)}
+	}
+	holes := []ScorecardHole{
+		{HoleNumber: 1, Par: iptr(4), Scores: []ScorecardScore{box("a1", 5, 4), box("b1", 4, 4)}},
+		{HoleNumber: 2, Par: iptr(3), Scores: []ScorecardScore{box("a1", 3, 3), box("b1", 4, 4)}},
+		{HoleNumber: 3, Par: iptr(5), Scores: []ScorecardScore{box("a1", 6, 6), {UserID: "b1"}}}, // b1 still to post
+	}
+	results := map[int]HoleResult{
+		1: {HoleNumber: 1, Team1Points: 0.5, Team2Points: 0.5},
+		2: {HoleNumber: 2, Team1Points: 1},
+	}
+
+	total := scorecardTotal("Total", holes, results)
+
+	if total.Label != "Total" || total.Par == nil || *total.Par != 12 {
+		t.Errorf("label %q par %v, want Total par 12", total.Label, optionalInt(total.Par))
+	}
+	wantPlayers := []ScorecardPlayerSum{{UserID: "a1", Gross: 14, Net: 13}, {UserID: "b1", Gross: 8, Net: 8}}
+	if !reflect.DeepEqual(total.Players, wantPlayers) {
+		t.Errorf("players = %+v, want %+v", total.Players, wantPlayers)
+	}
+	if total.Team1Points != 1.5 || total.Team2Points != 0.5 {
+		t.Errorf("points = %v-%v, want 1.5-0.5", total.Team1Points, total.Team2Points)
+	}
+
+	holes[1].Par = nil
+	if par := scorecardTotal("Total", holes, results).Par; par != nil {
+		t.Errorf("par = %d with a hole missing par, want none", *par)
+	}
+}
+
+func TestScorecardSubtotals(t *testing.T) {
+	tests := []struct {
+		name   string
+		match  models.Match
+		labels []string
+		holes  []int // holes counted in each subtotal
+	}{
+		{"front nine only", models.Match{Holes: 3, StartingHole: 1}, []string{"Total"}, []int{3}},
+		{"back nine only", models.Match{Holes: 3, StartingHole: 12}, []string{"Total"}, []int{3}},
+		{"across the turn", models.Match{Holes: 5, StartingHole: 7}, []string{"Out", "In", "Total"}, []int{3, 2, 5}},
+		{"shotgun start round the end", models.Match{Holes: 4, StartingHole: 18}, []string{"Out", "In", "Total"}, []int{3, 1, 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := singlesContext(18, matchPlayScorer{})
+			tt.match.ID, tt.match.Team1ID, tt.match.Team2ID = "m1", "t1", "t2"
+			ctx.match, ctx.holes = &tt.match, tt.match.HolesOn(18)
+
+			// Every hole is halved in 4, so each subtotal's gross and points show how many holes it counts
+			var scores []models.Score
+			for _, holeNum := range ctx.holes {
+				scores = append(scores, postScores(holeNum, map[string]int{"a1": 4, "b1": 4})...)
+			}
+			status, err := ctx.matchStatus(scores, nil, nil)
+			if err != nil {
+				t.Fatalf("matchStatus: %v", err)
+			}
+			_, subtotals := ctx.scorecardHoles(scores, nil, status)
+
+			var labels []string
+			for i, subtotal := range subtotals {
+				labels = append(labels, subtotal.Label)
+				if i >= len(tt.holes) {
+					continue
+				}
+				if gross := subtotal.Players[0].Gross; gross != 4*tt.holes[i] {
+					t.Errorf("%s gross = %d, want %d", subtotal.Label, gross, 4*tt.holes[i])
+				}
+				if subtotal.Team1Points != 0.5*float64(tt.holes[i]) {
+					t.Errorf("%s team 1 points = %v, want %v", subtotal.Label, subtotal.Team1Points, 0.5*float64(tt.holes[i]))
+				}
+			}
+			if !reflect.DeepEqual(labels, tt.labels) {
+				t.Errorf("subtotals = %v, want %v", labels, tt.labels)
+			}
+		})
+	}
+}
+
+func TestScorecardPlayoffHoles(t *testing.T) {
+	won, lost, halved := [2]int{3, 4}, [2]int{5, 4}, [2]int{4, 4}
+	ctx := singlesContext(3, matchPlayScorer{})
+	ctx.match.MustProduceWinner = true
+
+	// Level after three holes, the first extra hole halved and a1 the only one through the second
+	holeScores := singles(won, lost, halved, halved)
+	var scores []models.Score
+	for holeNum := 1; holeNum <= 4; holeNum++ {
+		scores = append(scores, holeScores[holeNum]...)
+	}
+	scores = append(scores, postScores(5, map[string]int{"a1": 4})...)
+
+	status, err := ctx.matchStatus(scores, nil, nil)
+	if err != nil {
+		t.Fatalf("matchStatus: %v", err)
+	}
+	holes, subtotals := ctx.scorecardHoles(scores, nil, status)
+
+	type column struct {
+		hole    int
+		playoff bool
+	}
+	want := []column{{1, false}, {2, false}, {3, false}, {4, true}, {5, true}}
+	var got []column
+	for _, hole := range holes {
+		got = append(got, column{hole.HoleNumber, hole.Playoff})
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("holes = %+v, want %+v", got, want)
+	}
+	// The halved extra hole leaves the match level; the one still being played has no result
+	if up := holes[3].Team1Up; up == nil || *up != 0 {
+		t.Errorf("first playoff hole margin = %s, want 0", optionalInt(up))
+	}
+	if up := holes[4].Team1Up; up != nil {
+		t.Errorf("unfinished playoff hole margin = %d, want none", *up)
+	}
+
+	if len(subtotals) != 1 || subtotals[0].Players[0].Gross != 3+5+4 {
+		t.Errorf("subtotals = %+v, want one Total over the regulation holes", subtotals)
+	}
+}
+
+func optionalInt(value *int) string {
+	if value == nil {
+		return "nil"
+	}
+	return fmt.Sprint(*value)
+}
@@ -0,0 +1,351 @@
+package scoring
+
+import (
+	"fmt"
+
+	"mayhamapi/models"
+)
+
+// FormatScorer scores a single hole for one match format
+type FormatScorer interface {
+	ScoreHole(hole *HoleInput) (*HoleResult, error)
+}
+
+// teamBallScorer is implemented by formats where each side plays a single ball,
+// so one posted score per side completes the hole
+type teamBallScorer interface {
+	FormatScorer
+	teamBall()
+}
+
+// totalScorer is implemented by formats decided on cumulative team score rather
+// than holes won. Their hole results carry team scores but no hole points.
+type totalScorer interface {
+	FormatScorer
+	lowerWins() bool
+}
+
+// contestScorer is implemented by formats that can decide more than one point contest
+// per hole. contestsPerHole is the points at stake on each hole; when that's more than
+// one, the match margin is counted in points.
+type contestScorer interface {
+	FormatScorer
+	contestsPerHole() int
+}
+
+// HoleInput holds everything a FormatScorer needs to score one hole
+type HoleInput struct {
+	Match      *models.Match
+	HoleNumber int
+	Par        *int // nil when the round has no course data
+	Team1      []models.Score
+	Team2      []models.Score
+	Strokes    map[string]int // handicap strokes received on this hole, by user ID
+}
+
+// net returns a player's score after handicap strokes
+func (h *HoleInput) net(score models.Score) int {
+	return score.Counted() - h.Strokes[score.UserID]
+}
+
+func (h *HoleInput) minNet(scores []models.Score) int {
+	best := h.net(scores[0])
+	for _, score := range scores {
+		if h.net(score) < best {
+			best = h.net(score)
+		}
+	}
+	return best
+}
+
+func (h *HoleInput) maxNet(scores []models.Score) int {
+	worst := h.net(scores[0])
+	for _, score := range scores {
+		if h.net(score) > worst {
+			worst = h.net(score)
+		}
+	}
+	return worst
+}
+
+func (h *HoleInput) playerScores() []models.Score {
+	scores := make([]models.Score, 0, len(h.Team1)+len(h.Team2))
+	scores = append(scores, h.Team1...)
+	return append(scores, h.Team2...)
+}
+
+// newFormatScorer returns the scorer for a match format's scoring_type
+func newFormatScorer(format *models.MatchFormatDefinition) (FormatScorer, error) {
+	switch format.ScoringType {
+	case models.MatchPlay:
+		return matchPlayScorer{}, nil
+	case models.Scramble:
+		return scrambleScorer{}, nil
+	case models.BestBall:
+		return bestBallScorer{}, nil
+	case models.AlternateShot:
+		return alternateShotScorer{}, nil
+	case models.HighLow:
+		return highLowScorer{split: format.Config.HighLowScoring == models.HighLowSplit}, nil
+	case models.Shamble:
+		return shambleScorer{}, nil
+	case models.StrokePlay:
+		return strokePlayScorer{}, nil
+	case models.Stableford:
+		points := format.Config.StablefordPoints
+		if len(points) == 0 {
+			points = models.DefaultStablefordPoints
+		}
+		return stablefordScorer{points: points}, nil
+	case models.Nassau:
+		return nassauScorer{autoPress: format.Config.AutoPress}, nil
+	case models.Custom:
+		return newCustomScorer(format.Config), nil
+	default:
+		return nil, fmt.Errorf("unsupported scoring type: %s", format.ScoringType)
+	}
+}
+
+// matchPlayScorer handles singles match play: one player per side, lowest score wins the hole
+type matchPlayScorer struct{}
+
+func (matchPlayScorer) ScoreHole(hole *HoleInput) (*HoleResult, error) {
+	if len(hole.Team1) == 0 || len(hole.Team2) == 0 {
+		return nil, fmt.Errorf("insufficient scores for match play")
+	}
+
+	return decideHole(hole, hole.minNet(hole.Team1), hole.minNet(hole.Team2)), nil
+}
+
+// bestBallScorer takes the lowest individual score on each side
+type bestBallScorer struct{}
+
+func (bestBallScorer) ScoreHole(hole *HoleInput) (*HoleResult, error) {
+	if len(hole.Team1) == 0 || len(hole.Team2) == 0 {
+		return nil, fmt.Errorf("insufficient scores for best ball")
+	}
+
+	return decideHole(hole, hole.minNet(hole.Team1), hole.minNet(hole.Team2)), nil
+}
+
+// scrambleScorer expects a single team score per side, posted by any team member
+type scrambleScorer struct{}
+
+func (scrambleScorer) teamBall() {}
+
+func (scrambleScorer) ScoreHole(hole *HoleInput) (*HoleResult, error) {
+	if len(hole.Team1) == 0 || len(hole.Team2) == 0 {
+		return nil, fmt.Errorf("insufficient scores for scramble")
+	}
+
+	return decideHole(hole, hole.net(hole.Team1[0]), hole.net(hole.Team2[0])), nil
+}
+
+// alternateShotScorer plays one ball per side, so each team posts a single score
+type alternateShotScorer struct{}
+
+func (alternateShotScorer) teamBall() {}
+
+func (alternateShotScorer) ScoreHole(hole *HoleInput) (*HoleResult, error) {
+	if len(hole.Team1) == 0 || len(hole.Team2) == 0 {
+		return nil, fmt.Errorf("insufficient scores for alternate shot")
+	}
+
+	return decideHole(hole, hole.net(hole.Team1[0]), hole.net(hole.Team2[0])), nil
+}
+
+// highLowScorer compares each team's lowest and highest scores. Combined scoring adds
+// them into one team total worth a point; split scoring plays low ball against low ball
+// and high ball against high ball for a point each.
+type highLowScorer struct {
+	split bool
+}
+
+func (sc highLowScorer) contestsPerHole() int {
+	if sc.split {
+		return 2
+	}
+	return 1
+}
+
+func (sc highLowScorer) ScoreHole(hole *HoleInput) (*HoleResult, error) {
+	if len(hole.Team1) < 2 || len(hole.Team2) < 2 {
+		return nil, fmt.Errorf("high-low requires at least 2 players per team")
+	}
+
+	if sc.split {
+		return contestHole(hole,
+			newContest(hole, "low", hole.minNet(hole.Team1), hole.minNet(hole.Team2)),
+			newContest(hole, "high", hole.maxNet(hole.Team1), hole.maxNet(hole.Team2)),
+		), nil
+	}
+
+	team1Total := hole.minNet(hole.Team1) + hole.maxNet(hole.Team1)
+	team2Total := hole.minNet(hole.Team2) + hole.maxNet(hole.Team2)
+
+	return decideHole(hole, team1Total, team2Total), nil
+}
+
+// shambleScorer plays individual balls after the chosen drive, so the best ball counts
+type shambleScorer struct{}
+
+func (shambleScorer) ScoreHole(hole *HoleInput) (*HoleResult, error) {
+	if len(hole.Team1) == 0 || len(hole.Team2) == 0 {
+		return nil, fmt.Errorf("insufficient scores for shamble")
+	}
+
+	return decideHole(hole, hole.minNet(hole.Team1), hole.minNet(hole.Team2)), nil
+}
+
+// strokePlayScorer adds up every player's (net) strokes; the lowest team total over the match wins
+type strokePlayScorer struct{}
+
+func (strokePlayScorer) lowerWins() bool { return true }
+
+func (strokePlayScorer) ScoreHole(hole *HoleInput) (*HoleResult, error) {
+	if len(hole.Team1) == 0 || len(hole.Team2) == 0 {
+		return nil, fmt.Errorf("insufficient scores for stroke play")
+	}
+
+	team1Total, team2Total := 0, 0
+	for _, score := range hole.Team1 {
+		team1Total += hole.net(score)
+	}
+	for _, score := range hole.Team2 {
+		team2Total += hole.net(score)
+	}
+
+	return totalHole(hole, team1Total, team2Total), nil
+}
+
+// stablefordScorer awards each player points for their (net) score relative to par;
+// the highest team points total over the match wins
+type stablefordScorer struct {
+	points map[int]int
+}
+
+func (stablefordScorer) lowerWins() bool { return false }
+
+func (sc stablefordScorer) ScoreHole(hole *HoleInput) (*HoleResult, error) {
+	if len(hole.Team1) == 0 || len(hole.Team2) == 0 {
+		return nil, fmt.Errorf("insufficient scores for stableford")
+	}
+	if hole.Par == nil {
+		return nil, fmt.Errorf("stableford requires par for hole %d; set a course and tee on the round", hole.HoleNumber)
+	}
+
+	team1Total, team2Total := 0, 0
+	for _, score := range hole.Team1 {
+		team1Total += sc.playerPoints(hole, score)
+	}
+	for _, score := range hole.Team2 {
+		team2Total += sc.playerPoints(hole, score)
+	}
+
+	return totalHole(hole, team1Total, team2Total), nil
+}
+
+func (sc stablefordScorer) playerPoints(hole *HoleInput, score models.Score) int {
+	return stablefordPoints(sc.points, hole.net(score)-*hole.Par)
+}
+
+// stablefordPoints looks up the points for a score relative to par. Scores better
+// than the best entry in the table earn that entry's points; worse scores earn 0.
+func stablefordPoints(table map[int]int, relativeToPar int) int {
+	if points, ok := table[relativeToPar]; ok {
+		return points
+	}
+
+	best, found := 0, false
+	for rel := range table {
+		if !found || rel < best {
+			best, found = rel, true
+		}
+	}
+	if found && relativeToPar < best {
+		return table[best]
+	}
+	return 0
+}
+
+// totalHole records team scores for a hole without awarding hole points
+func totalHole(hole *HoleInput, team1Score, team2Score int) *HoleResult {
+	return &HoleResult{
+		HoleNumber:   hole.HoleNumber,
+		Par:          hole.Par,
+		Team1Score:   &team1Score,
+		Team2Score:   &team2Score,
+		PlayerScores: hole.playerScores(),
+	}
+}
+
+// decideHole awards one point to the lower (net) team score, or half a point each on a tie
+func decideHole(hole *HoleInput, team1Score, team2Score int) *HoleResult {
+	result := &HoleResult{
+		HoleNumber:   hole.HoleNumber,
+		Par:          hole.Par,
+		Team1Score:   &team1Score,
+		Team2Score:   &team2Score,
+		PlayerScores: hole.playerScores(),
+	}
+
+	if team1Score < team2Score {
+		result.Team1Points = 1
+		result.WinnerTeamID = &hole.Match.Team1ID
+	} else if team2Score < team1Score {
+		result.Team2Points = 1
+		result.WinnerTeamID = &hole.Match.Team2ID
+	} else {
+		result.Team1Points = 0.5
+		result.Team2Points = 0.5
+	}
+
+	return result
+}
+
+// newContest decides one point contest on a hole: a point to the lower (net) score, or half each on a tie
+func newContest(hole *HoleInput, name string, team1Score, team2Score int) models.HoleContest {
+	contest := models.HoleContest{Name: name, Team1Score: team1Score, Team2Score: team2Score}
+
+	if team1Score < team2Score {
+		contest.Team1Points = 1
+		contest.WinnerTeamID = &hole.Match.Team1ID
+	} else if team2Score < team1Score {
+		contest.Team2Points = 1
+		contest.WinnerTeamID = &hole.Match.Team2ID
+	} else {
+		contest.Team1Points = 0.5
+		contest.Team2Points = 0.5
+	}
+
+	return contest
+}
+
+// contestHole combines several point contests into one hole result. The team scores
+// are the contest scores added together, and the hole goes to the side with more points.
+func contestHole(hole *HoleInput, contests ...models.HoleContest) *HoleResult {
+	result := &HoleResult{
+		HoleNumber:   hole.HoleNumber,
+		Par:          hole.Par,
+		Contests:     contests,
+		PlayerScores: hole.playerScores(),
+	}
+
+	team1Score, team2Score := 0, 0
+	for _, contest := range contests {
+		team1Score += contest.Team1Score
+		team2Score += contest.Team2Score
+		result.Team1Points += contest.Team1Points
+		result.Team2Points += contest.Team2Points
+	}
+	result.Team1Score = &team1Score
+	result.Team2Score = &team2Score
+
+	if result.Team1Points > result.Team2Points {
+		result.WinnerTeamID = &hole.Match.Team1ID
+	} else if result.Team2Points > result.Team1Points {
+		result.WinnerTeamID = &hole.Match.Team2ID
+	}
+
+	return result
+}
@@ -0,0 +1,436 @@
+package scoring
+
+import (
+	"reflect"
+	"testing"
+
+	"mayhamapi/models"
+)
+
+// testMatch is a match between teams t1 and t2 over the first holes of the course
+func testMatch(holes int) *models.Match {
+	return &models.Match{ID: "m1", Team1ID: "t1", Team2ID: "t2", Holes: holes, StartingHole: 1}
+}
+
+// postScores turns strokes by user ID into a side's posted scores for a hole
+func postScores(holeNumber int, strokes map[string]int) []models.Score {
+	scores := make([]models.Score, 0, len(strokes))
+	for userID, n := range strokes {
+		scores = append(scores, models.Score{MatchID: "m1", UserID: userID, HoleNumber: holeNumber, Strokes: n})
+	}
+	return scores
+}
+
+func TestScoreHole(t *testing.T) {
+	tests := []struct {
+		name         string
+		scorer       FormatScorer
+		team1, team2 map[string]int
+		strokes      map[string]int // handicap strokes received on the hole
+		team1Score   int
+		team2Score   int
+		winner       string // empty for a halved hole
+		team1Points  float64
+		team2Points  float64
+	}{
+		{"match play lower score wins", matchPlayScorer{}, map[string]int{"a1": 4}, map[string]int{"b1": 5}, nil, 4, 5, "t1", 1, 0},
+		{"match play equal scores halve", matchPlayScorer{}, map[string]int{"a1": 4}, map[string]int{"b1": 4}, nil, 4, 4, "", 0.5, 0.5},
+		{"match play stroke halves the hole", matchPlayScorer{}, map[string]int{"a1": 4}, map[string]int{"b1": 5}, map[string]int{"b1": 1}, 4, 4, "", 0.5, 0.5},
+		{"match play stroke wins the hole", matchPlayScorer{}, map[string]int{"a1": 4}, map[string]int{"b1": 4}, map[string]int{"b1": 1}, 4, 3, "t2", 0, 1},
+		{"best ball takes each side's lowest", bestBallScorer{}, map[string]int{"a1": 6, "a2": 3}, map[string]int{"b1": 4, "b2": 4}, nil, 3, 4, "t1", 1, 0},
+		{"best ball counts net scores", bestBallScorer{}, map[string]int{"a1": 5, "a2": 6}, map[string]int{"b1": 4, "b2": 5}, map[string]int{"a1": 2}, 3, 4, "t1", 1, 0},
+		{"best ball halve", bestBallScorer{}, map[string]int{"a1": 4, "a2": 5}, map[string]int{"b1": 6, "b2": 4}, nil, 4, 4, "", 0.5, 0.5},
+		{"scramble team score", scrambleScorer{}, map[string]int{"a1": 3}, map[string]int{"b2": 4}, nil, 3, 4, "t1", 1, 0},
+		{"scramble team stroke halves", scrambleScorer{}, map[string]int{"a1": 3}, map[string]int{"b2": 4}, map[string]int{"b2": 1}, 3, 3, "", 0.5, 0.5},
+		{"alternate shot lower ball wins", alternateShotScorer{}, map[string]int{"a2": 5}, map[string]int{"b1": 4}, nil, 5, 4, "t2", 0, 1},
+		{"alternate shot net", alternateShotScorer{}, map[string]int{"a2": 5}, map[string]int{"b1": 4}, map[string]int{"a2": 2}, 3, 4, "t1", 1, 0},
+		{"high-low combined total", highLowScorer{}, map[string]int{"a1": 3, "a2": 6}, map[string]int{"b1": 4, "b2": 4}, nil, 9, 8, "t2", 0, 1},
+		{"high-low combined halve", highLowScorer{}, map[string]int{"a1": 3, "a2": 5}, map[string]int{"b1": 4, "b2": 4}, nil, 8, 8, "", 0.5, 0.5},
+		{"high-low combined net", highLowScorer{}, map[string]int{"a1": 3, "a2": 6}, map[string]int{"b1": 4, "b2": 4}, map[string]int{"a2": 2}, 7, 8, "t1", 1, 0},
+		{"high-low split shares the hole", highLowScorer{split: true}, map[string]int{"a1": 3, "a2": 6}, map[string]int{"b1": 4, "b2": 4}, nil, 9, 8, "", 1, 1},
+		{"high-low split sweeps both", highLowScorer{split: true}, map[string]int{"a1": 3, "a2": 4}, map[string]int{"b1": 4, "b2": 5}, nil, 7, 9, "t1", 2, 0},
+		{"high-low split halves low ball", highLowScorer{split: true}, map[string]int{"a1": 4, "a2": 5}, map[string]int{"b1": 4, "b2": 6}, nil, 9, 10, "t1", 1.5, 0.5},
+		{"shamble best ball", shambleScorer{}, map[string]int{"a1": 5, "a2": 5}, map[string]int{"b1": 4, "b2": 7}, nil, 5, 4, "t2", 0, 1},
+		{"shamble net halve", shambleScorer{}, map[string]int{"a1": 5, "a2": 5}, map[string]int{"b1": 4, "b2": 7}, map[string]int{"a2": 1}, 4, 4, "", 0.5, 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hole := &HoleInput{
+				Match:      testMatch(18),
+				HoleNumber: 1,
+				Team1:      postScores(1, tt.team1),
+				Team2:      postScores(1, tt.team2),
+				Strokes:    tt.strokes,
+			}
+			result, err := tt.scorer.ScoreHole(hole)
+			if err != nil {
+				t.Fatalf("ScoreHole: %v", err)
+			}
+
+			if *result.Team1Score != tt.team1Score || *result.Team2Score != tt.team2Score {
+				t.Errorf("team scores = %d-%d, want %d-%d", *result.Team1Score, *result.Team2Score, tt.team1Score, tt.team2Score)
+			}
+			winner := ""
+			if result.WinnerTeamID != nil {
+				winner = *result.WinnerTeamID
+			}
+			if winner != tt.winner {
+				t.Errorf("winner = %q, want %q", winner, tt.winner)
+			}
+			if result.Team1Points != tt.team1Points || result.Team2Points != tt.team2Points {
+				t.Errorf("points = %v-%v, want %v-%v", result.Team1Points, result.Team2Points, tt.team1Points, tt.team2Points)
+			}
+		})
+	}
+}
+
+func TestScoreHoleInsufficientScores(t *testing.T) {
+	tests := []struct {
+		name         string
+		scorer       FormatScorer
+		team1, team2 map[string]int
+	}{
+		{"match play", matchPlayScorer{}, map[string]int{"a1": 4}, nil},
+		{"best ball", bestBallScorer{}, nil, map[string]int{"b1": 4}},
+		{"scramble", scrambleScorer{}, nil, nil},
+		{"alternate shot", alternateShotScorer{}, map[string]int{"a1": 4}, nil},
+		{"high-low", highLowScorer{}, map[string]int{"a1": 4}, map[string]int{"b1": 4, "b2": 5}},
+		{"shamble", shambleScorer{}, nil, map[string]int{"b1": 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hole := &HoleInput{Match: testMatch(18), HoleNumber: 1, Team1: postScores(1, tt.team1), Team2: postScores(1, tt.team2)}
+			if _, err := tt.scorer.ScoreHole(hole); err == nil {
+				t.Error("ScoreHole succeeded, want an error")
+			}
+		})
+	}
+}
+
+// singles posts a1's and b1's strokes on consecutive holes from hole 1
+func singles(strokes ...[2]int) map[int][]models.Score {
+	return singlesFrom(1, strokes...)
+}
+
+// singlesFrom posts a1's and b1's strokes on consecutive holes from firstHole
+func singlesFrom(firstHole int, strokes ...[2]int) map[int][]models.Score {
+	holeScores := make(map[int][]models.Score)
+	for i, pair := range strokes {
+		holeNum := firstHole + i
+		holeScores[holeNum] = append(postScores(holeNum, map[string]int{"a1": pair[0]}), postScores(holeNum, map[string]int{"b1": pair[1]})...)
+	}
+	return holeScores
+}
+
+func TestRegulationStatus(t *testing.T) {
+	won, lost, halved := [2]int{3, 4}, [2]int{5, 4}, [2]int{4, 4}
+
+	tests := []struct {
+		name           string
+		holes          int
+		scorer         FormatScorer
+		roster         *MatchRoster
+		allocation     StrokeAllocation
+		holeScores     map[int][]models.Score
+		team1Up        int
+		holesCompleted int
+		complete       bool
+		closedOut      bool
+		dormie         bool
+		winner         string
+		resultText     string
+	}{
+		{
+			name: "in progress", holes: 9, scorer: matchPlayScorer{},
+			holeScores: singles(won, halved, lost, won),
+			team1Up:    1, holesCompleted: 4, resultText: "1 UP",
+		},
+		{
+			name: "closed out", holes: 9, scorer: matchPlayScorer{},
+			holeScores: singles(won, won, won, won, won, halved, halved),
+			team1Up:    5, holesCompleted: 5, complete: true, closedOut: true, winner: "t1", resultText: "5&4",
+		},
+		{
+			name: "closed out on the last hole it could be", holes: 9, scorer: matchPlayScorer{},
+			holeScores: singles(lost, lost, halved, lost, halved, halved, halved),
+			team1Up:    -3, holesCompleted: 7, complete: true, closedOut: true, winner: "t2", resultText: "3&2",
+		},
+		{
+			name: "dormie", holes: 9, scorer: matchPlayScorer{},
+			holeScores: singles(won, won, halved, halved, halved, halved, halved),
+			team1Up:    2, holesCompleted: 7, dormie: true, resultText: "2 UP",
+		},
+		{
+			name: "won 1 up from dormie", holes: 3, scorer: matchPlayScorer{},
+			holeScores: singles(lost, halved, halved),
+			team1Up:    -1, holesCompleted: 3, complete: true, winner: "t2", resultText: "1 UP",
+		},
+		{
+			name: "halved", holes: 3, scorer: matchPlayScorer{},
+			holeScores:     singles(won, lost, halved),
+			holesCompleted: 3, complete: true, resultText: "Halved",
+		},
+		{
+			name: "all square in progress", holes: 9, scorer: matchPlayScorer{},
+			holeScores:     singles(halved, halved),
+			holesCompleted: 2, resultText: "A/S",
+		},
+		{
+			name: "net stroke halves a hole", holes: 3, scorer: matchPlayScorer{},
+			allocation:     StrokeAllocation{1: {"b1": 1}},
+			holeScores:     singles(won, halved, halved),
+			holesCompleted: 3, complete: true, resultText: "Halved",
+		},
+		{
+			name: "net stroke decides the match", holes: 3, scorer: matchPlayScorer{},
+			allocation: StrokeAllocation{3: {"b1": 1}},
+			holeScores: singles(won, lost, halved),
+			team1Up:    -1, holesCompleted: 3, complete: true, winner: "t2", resultText: "1 UP",
+		},
+		{
+			name: "unscored hole waits", holes: 3, scorer: matchPlayScorer{},
+			holeScores: map[int][]models.Score{1: singles(won)[1], 3: postScores(3, map[string]int{"a1": 4})},
+			team1Up:    1, holesCompleted: 1, resultText: "1 UP",
+		},
+		{
+			name: "best ball closed out", holes: 3, scorer: bestBallScorer{},
+			roster: &MatchRoster{Team1: []string{"a1", "a2"}, Team2: []string{"b1", "b2"}},
+			holeScores: map[int][]models.Score{
+				1: postScores(1, map[string]int{"a1": 3, "a2": 5, "b1": 4, "b2": 4}),
+				2: postScores(2, map[string]int{"a1": 4, "a2": 3, "b1": 4, "b2": 5}),
+			},
+			team1Up: 2, holesCompleted: 2, complete: true, closedOut: true, winner: "t1", resultText: "2&1",
+		},
+		{
+			name: "split high-low dormie in points", holes: 2, scorer: highLowScorer{split: true},
+			roster: &MatchRoster{Team1: []string{"a1", "a2"}, Team2: []string{"b1", "b2"}},
+			holeScores: map[int][]models.Score{
+				1: postScores(1, map[string]int{"a1": 3, "a2": 4, "b1": 4, "b2": 5}),
+			},
+			team1Up: 2, holesCompleted: 1, dormie: true, resultText: "2 pts",
+		},
+		{
+			name: "scramble needs one score a side", holes: 1, scorer: scrambleScorer{},
+			roster: &MatchRoster{Team1: []string{"a1", "a2"}, Team2: []string{"b1", "b2"}},
+			holeScores: map[int][]models.Score{
+				1: postScores(1, map[string]int{"a2": 3, "b1": 4}),
+			},
+			team1Up: 1, holesCompleted: 1, complete: true, winner: "t1", resultText: "1 UP",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match := testMatch(tt.holes)
+			roster := tt.roster
+			if roster == nil {
+				roster = &MatchRoster{Team1: []string{"a1"}, Team2: []string{"b1"}}
+			}
+			ctx := &matchContext{
+				match:      match,
+				scorer:     tt.scorer,
+				roster:     roster,
+				holes:      match.HolesOn(18),
+				course:     map[int]models.Hole{},
+				allocation: tt.allocation,
+			}
+
+			status, err := ctx.regulationStatus(tt.holeScores)
+			if err != nil {
+				t.Fatalf("regulationStatus: %v", err)
+			}
+
+			if status.Team1Up != tt.team1Up {
+				t.Errorf("Team1Up = %d, want %d", status.Team1Up, tt.team1Up)
+			}
+			if status.HolesCompleted != tt.holesCompleted {
+				t.Errorf("HolesCompleted = %d, want %d", status.HolesCompleted, tt.holesCompleted)
+			}
+			if status.MatchComplete != tt.complete || status.ClosedOut != tt.closedOut || status.Dormie != tt.dormie {
+				t.Errorf("complete, closed out, dormie = %v, %v, %v, want %v, %v, %v",
+					status.MatchComplete, status.ClosedOut, status.Dormie, tt.complete, tt.closedOut, tt.dormie)
+			}
+			winner := ""
+			if status.WinnerTeamID != nil {
+				winner = *status.WinnerTeamID
+			}
+			if winner != tt.winner {
+				t.Errorf("winner = %q, want %q", winner, tt.winner)
+			}
+			if status.ResultText != tt.resultText {
+				t.Errorf("ResultText = %q, want %q", status.ResultText, tt.resultText)
+			}
+		})
+	}
+}
+
+func TestStablefordPoints(t *testing.T) {
+	tests := []struct {
+		name          string
+		table         map[int]int
+		relativeToPar int
+		want          int
+	}{
+		{"albatross", models.DefaultStablefordPoints, -3, 5},
+		{"eagle", models.DefaultStablefordPoints, -2, 4},
+		{"birdie", models.DefaultStablefordPoints, -1, 3},
+		{"par", models.DefaultStablefordPoints, 0, 2},
+		{"bogey", models.DefaultStablefordPoints, 1, 1},
+		{"double bogey", models.DefaultStablefordPoints, 2, 0},
+		{"better than the table", models.DefaultStablefordPoints, -4, 5},
+		{"custom table", map[int]int{-1: 2, 0: 0, 1: -1, 2: -3}, 2, -3},
+		{"worse than a custom table", map[int]int{-1: 2, 0: 0, 1: -1, 2: -3}, 3, 0},
+		{"better than a custom table", map[int]int{-1: 2, 0: 0, 1: -1, 2: -3}, -2, 2},
+		{"empty table", map[int]int{}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stablefordPoints(tt.table, tt.relativeToPar); got != tt.want {
+				t.Errorf("stablefordPoints = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTotalScoreHole(t *testing.T) {
+	par4 := 4
+	stableford := stablefordScorer{points: models.DefaultStablefordPoints}
+
+	tests := []struct {
+		name         string
+		scorer       FormatScorer
+		team1, team2 map[string]int
+		strokes      map[string]int
+		team1Score   int
+		team2Score   int
+	}{
+		{"stroke play adds every score", strokePlayScorer{}, map[string]int{"a1": 4, "a2": 6}, map[string]int{"b1": 5, "b2": 5}, nil, 10, 10},
+		{"stroke play nets each player", strokePlayScorer{}, map[string]int{"a1": 4, "a2": 6}, map[string]int{"b1": 5, "b2": 5}, map[string]int{"a2": 1, "b1": 2}, 9, 8},
+		{"stableford adds every player's points", stableford, map[string]int{"a1": 3, "a2": 6}, map[string]int{"b1": 4, "b2": 5}, nil, 3, 3},
+		{"stableford nets each player", stableford, map[string]int{"a1": 3, "a2": 6}, map[string]int{"b1": 4, "b2": 5}, map[string]int{"a2": 1, "b2": 1}, 4, 4},
+		{"stableford custom table", stablefordScorer{points: map[int]int{-1: 2, 0: 0, 1: -1}}, map[string]int{"a1": 3}, map[string]int{"b1": 5}, nil, 2, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hole := &HoleInput{Match: testMatch(18), HoleNumber: 1, Par: &par4,
+				Team1: postScores(1, tt.team1), Team2: postScores(1, tt.team2), Strokes: tt.strokes}
+			result, err := tt.scorer.ScoreHole(hole)
+			if err != nil {
+				t.Fatalf("ScoreHole: %v", err)
+			}
+
+			if *result.Team1Score != tt.team1Score || *result.Team2Score != tt.team2Score {
+				t.Errorf("team scores = %d-%d, want %d-%d", *result.Team1Score, *result.Team2Score, tt.team1Score, tt.team2Score)
+			}
+			// Total formats are decided over the match, not hole by hole
+			if result.WinnerTeamID != nil || result.Team1Points != 0 || result.Team2Points != 0 {
+				t.Errorf("hole awarded %v-%v to %v, want no hole result", result.Team1Points, result.Team2Points, *result.WinnerTeamID)
+			}
+		})
+	}
+}
+
+func TestStablefordScoreHoleNeedsPar(t *testing.T) {
+	hole := &HoleInput{Match: testMatch(18), HoleNumber: 1,
+		Team1: postScores(1, map[string]int{"a1": 4}), Team2: postScores(1, map[string]int{"b1": 4})}
+	if _, err := (stablefordScorer{points: models.DefaultStablefordPoints}).ScoreHole(hole); err == nil {
+		t.Error("ScoreHole succeeded without par, want an error")
+	}
+}
+
+func TestHighLowContests(t *testing.T) {
+	type contest struct {
+		name                     string
+		team1Score, team2Score   int
+		team1Points, team2Points float64
+		winner                   string
+	}
+	tests := []struct {
+		name         string
+		team1, team2 map[string]int
+		strokes      map[string]int
+		contests     []contest
+	}{
+		{
+			name:  "each ball won by a different side",
+			team1: map[string]int{"a1": 3, "a2": 6}, team2: map[string]int{"b1": 4, "b2": 5},
+			contests: []contest{{"low", 3, 4, 1, 0, "t1"}, {"high", 6, 5, 0, 1, "t2"}},
+		},
+		{
+			name:  "halved high ball",
+			team1: map[string]int{"a1": 4, "a2": 5}, team2: map[string]int{"b1": 3, "b2": 5},
+			contests: []contest{{"low", 4, 3, 0, 1, "t2"}, {"high", 5, 5, 0.5, 0.5, ""}},
+		},
+		{
+			name:  "strokes can move a player between low and high ball",
+			team1: map[string]int{"a1": 4, "a2": 6}, team2: map[string]int{"b1": 4, "b2": 5},
+			strokes:  map[string]int{"a2": 3},
+			contests: []contest{{"low", 3, 4, 1, 0, "t1"}, {"high", 4, 5, 1, 0, "t1"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hole := &HoleInput{Match: testMatch(18), HoleNumber: 1, Team1: postScores(1, tt.team1), Team2: postScores(1, tt.team2), Strokes: tt.strokes}
+			result, err := highLowScorer{split: true}.ScoreHole(hole)
+			if err != nil {
+				t.Fatalf("ScoreHole: %v", err)
+			}
+
+			var got []contest
+			for _, c := range result.Contests {
+				winner := ""
+				if c.WinnerTeamID != nil {
+					winner = *c.WinnerTeamID
+				}
+				got = append(got, contest{c.Name, c.Team1Score, c.Team2Score, c.Team1Points, c.Team2Points, winner})
+			}
+			if !reflect.DeepEqual(got, tt.contests) {
+				t.Errorf("contests = %+v, want %+v", got, tt.contests)
+			}
+		})
+	}
+}
+
+func TestHighLowScoringConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		scoring  string
+		contests int // per hole; combined scoring plays the hole as one contest
+		listed   int // contests listed on the hole result
+	}{
+		{"combined by default", "", 1, 0},
+		{"combined", models.HighLowCombined, 1, 0},
+		{"split", models.HighLowSplit, 2, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scorer, err := newFormatScorer(&models.MatchFormatDefinition{ScoringType: models.HighLow, Config: models.FormatConfig{HighLowScoring: tt.scoring}})
+			if err != nil {
+				t.Fatalf("newFormatScorer: %v", err)
+			}
+			contests := 1
+			if c, ok := scorer.(contestScorer); ok {
+				contests = c.contestsPerHole()
+			}
+			if contests != tt.contests {
+				t.Errorf("contests per hole = %d, want %d", contests, tt.contests)
+			}
+
+			hole := &HoleInput{Match: testMatch(18), HoleNumber: 1,
+				Team1: postScores(1, map[string]int{"a1": 3, "a2": 6}), Team2: postScores(1, map[string]int{"b1": 4, "b2": 5})}
+			result, err := scorer.ScoreHole(hole)
+			if err != nil {
+				t.Fatalf("ScoreHole: %v", err)
+			}
+			if len(result.Contests) != tt.listed {
+				t.Errorf("hole result lists %d contests, want %d", len(result.Contests), tt.listed)
+			}
+		})
+	}
+}
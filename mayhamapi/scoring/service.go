@@ -2,16 +2,18 @@ package scoring
 
 import (
 	"fmt"
+	"math"
 	"mayhamapi/models"
 	"mayhamapi/repository"
 )
 
 type ScoringService struct {
 	repo *repository.Repository
+	odds *oddsCache
 }
 
 func NewScoringService(repo *repository.Repository) *ScoringService {
-	return &ScoringService{repo: repo}
+	return &ScoringService{repo: repo, odds: newOddsCache()}
 }
 
 // MatchStatus represents the current status of a match
@@ -22,266 +24,322 @@ type MatchStatus struct {
 	HolesRemaining   int     `json:"holes_remaining"`
 	MatchComplete    bool    `json:"match_complete"`
 	WinnerTeamID     *string `json:"winner_team_id"`
+	// Team1Up is the margin from team 1's side: positive when team 1 leads, negative when team 2 does.
+	// It counts holes for match play formats, and strokes or points for stroke play and Stableford.
+	Team1Up      int     `json:"team1_up"`
+	ScoringUnit  string  `json:"scoring_unit"` // holes, strokes or points
+	Team1Total   *int    `json:"team1_total,omitempty"`
+	Team2Total   *int    `json:"team2_total,omitempty"`
+	LeaderTeamID *string `json:"leader_team_id"`
+	Dormie       bool    `json:"dormie"`
+	ClosedOut    bool    `json:"closed_out"`
+	// HolesUnplayed counts the holes left unplayed when a match was closed out early
+	HolesUnplayed int    `json:"holes_unplayed"`
+	ResultText    string `json:"result_text"` // "3&2", "1 UP", "A/S" or "Halved"
+	// PlayingHandicaps is only populated for net matches
+	PlayingHandicaps map[string]int `json:"playing_handicaps,omitempty"`
+	// PlayerToPar is each player's gross score relative to par; only populated when the round has course data
+	PlayerToPar map[string]int `json:"player_to_par,omitempty"`
+	// Team1MatchPoints and Team2MatchPoints are the share of the match's points_available
+	// each team has earned; both are zero until the match is complete
+	Team1MatchPoints float64      `json:"team1_match_points"`
+	Team2MatchPoints float64      `json:"team2_match_points"`
+	HoleResults      []HoleResult `json:"hole_results"`
+	// Concessions lists every conceded hole, putt and match; a match concession ends the
+	// match immediately in favour of the other side
+	Concessions      []models.Concession `json:"concessions,omitempty"`
+	ConcededByTeamID *string             `json:"conceded_by_team_id,omitempty"`
+	// PlayoffHoles holds sudden-death holes played after a tied match that must produce a winner.
+	// They're numbered after the match's last hole and aren't counted in HolesCompleted or HoleResults.
+	PlayoffHoles []HoleResult `json:"playoff_holes,omitempty"`
+	InPlayoff    bool         `json:"in_playoff"`
+	// Segments is the Nassau breakdown: front, back and overall bets plus any presses.
+	// Nassau matches are won on segment points rather than holes.
+	Segments           []Segment `json:"segments,omitempty"`
+	Team1SegmentPoints float64   `json:"team1_segment_points,omitempty"`
+	Team2SegmentPoints float64   `json:"team2_segment_points,omitempty"`
+	// Probability is the live estimate of how the match finishes
+	Probability *models.MatchProbability `json:"probability"`
 }
 
 // HoleResult represents the result of a specific hole
 type HoleResult struct {
-	HoleNumber   int            `json:"hole_number"`
-	Team1Score   *int           `json:"team1_score"` // nil if format doesn't produce team score
-	Team2Score   *int           `json:"team2_score"`
-	WinnerTeamID *string        `json:"winner_team_id"` // nil for tie
-	Team1Points  float64        `json:"team1_points"`
-	Team2Points  float64        `json:"team2_points"`
-	PlayerScores []models.Score `json:"player_scores"`
+	HoleNumber   int     `json:"hole_number"`
+	Par          *int    `json:"par,omitempty"`
+	StrokeIndex  *int    `json:"stroke_index,omitempty"`
+	Team1Score   *int    `json:"team1_score"` // nil if format doesn't produce team score
+	Team2Score   *int    `json:"team2_score"`
+	WinnerTeamID *string `json:"winner_team_id"` // nil for tie
+	Team1Points  float64 `json:"team1_points"`
+	Team2Points  float64 `json:"team2_points"`
+	// Team1Up is the running match margin after this hole, measured like MatchStatus.Team1Up
+	Team1Up int `json:"team1_up"`
+	// Contests breaks the hole's points down when the format plays more than one contest per hole
+	Contests []models.HoleContest `json:"contests,omitempty"`
+	// ConcededByTeamID is set when the hole was conceded; Concessions lists the hole and putt concessions on it
+	ConcededByTeamID *string             `json:"conceded_by_team_id,omitempty"`
+	Concessions      []models.Concession `json:"concessions,omitempty"`
+	PlayerScores     []models.Score      `json:"player_scores"`
 }
 
 func (s *ScoringService) CalculateMatchStatus(match *models.Match, scores []models.Score) (*MatchStatus, error) {
+	ctx, err := s.loadMatchContext(match)
+	if err != nil {
+		return nil, err
+	}
+
+	concessions, err := s.repo.GetMatchConcessions(match.ID)
+	if err != nil {
+		return nil, err
+	}
+	var presses []models.Press
+	if _, ok := ctx.scorer.(nassauScorer); ok {
+		if presses, err = s.repo.GetMatchPresses(match.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return ctx.matchStatus(scores, concessions, presses)
+}
+
+// matchStatus scores the match from its posted scores, concessions and Nassau presses:
+// the regulation holes, any Nassau bets, a playoff when one is needed, and a conceded match
+func (ctx *matchContext) matchStatus(scores []models.Score, concessions []models.Concession, presses []models.Press) (*MatchStatus, error) {
+	match := ctx.match
+
 	// Group scores by hole
 	holeScores := make(map[int][]models.Score)
 	for _, score := range scores {
 		holeScores[score.HoleNumber] = append(holeScores[score.HoleNumber], score)
 	}
+	ctx.applyConcessions(concessions, holeScores)
+
+	status, err := ctx.regulationStatus(holeScores)
+	if err != nil {
+		return nil, err
+	}
+	if nassau, isNassau := ctx.scorer.(nassauScorer); isNassau {
+		status.Segments = nassauSegments(match, ctx.holes, status.HoleResults, presses, nassau.autoPress)
+		for _, seg := range status.Segments {
+			status.Team1SegmentPoints += seg.Team1Points
+			status.Team2SegmentPoints += seg.Team2Points
+		}
+
+		if status.MatchComplete {
+			status.WinnerTeamID = nil
+			switch {
+			case status.Team1SegmentPoints > status.Team2SegmentPoints:
+				status.WinnerTeamID = &match.Team1ID
+			case status.Team2SegmentPoints > status.Team1SegmentPoints:
+				status.WinnerTeamID = &match.Team2ID
+			}
+			status.LeaderTeamID = status.WinnerTeamID
+			status.ResultText = nassauResultText(status.Team1SegmentPoints, status.Team2SegmentPoints)
+		}
+	}
+	if match.MustProduceWinner && status.MatchComplete && status.WinnerTeamID == nil {
+		if err := ctx.playOff(holeScores, status); err != nil {
+			return nil, err
+		}
+	}
+	status.Concessions = concessions
+	for _, concession := range concessions {
+		if concession.Type == models.ConcedeMatch {
+			concede(match, status, concession.ConcedingTeamID)
+		}
+	}
+	status.Team1MatchPoints, status.Team2MatchPoints = matchPoints(match, status)
+	status.Probability = ctx.probability(status)
+
+	return status, nil
+}
 
+// regulationStatus scores the match's regulation holes in order from the scores posted so far,
+// stopping once the match is closed out, and works out the margin, dormie and result text
+func (ctx *matchContext) regulationStatus(holeScores map[int][]models.Score) (*MatchStatus, error) {
 	team1Points := 0.0
 	team2Points := 0.0
 	holesCompleted := 0
+	team1Up := 0
+	closedOut := false
+	team1Total, team2Total := 0, 0
+	totals, isTotalFormat := ctx.scorer.(totalScorer)
+	_, isNassau := ctx.scorer.(nassauScorer)
+	swing := ctx.swing()
+	holeResults := []HoleResult{}
+	var toPar map[string]int
+	if len(ctx.course) > 0 {
+		toPar = make(map[string]int)
+	}
 
 	// Calculate points for each completed hole
-	for holeNum := 1; holeNum <= match.Holes; holeNum++ {
-		holePlayerScores, exists := holeScores[holeNum]
-		if !exists || len(holePlayerScores) == 0 {
-			continue // Hole not played yet
-		}
-
-		// Check if all required players have submitted scores for this hole
-		// This is a simplified check - in a real scenario you'd need to know which players are in each team
-		if len(holePlayerScores) < 2 {
+	for _, holeNum := range ctx.holes {
+		hole := splitHoleScores(ctx.match, ctx.roster, holeNum, holeScores[holeNum])
+		hole.Strokes = ctx.allocation.forHole(holeNum)
+		hole.Par = ctx.par(holeNum)
+		if !ctx.holeDecided(hole) {
 			continue // Not all players have submitted scores
 		}
 
-		holeResult, err := s.calculateHoleResult(match, holeNum, holePlayerScores)
+		holeResult, err := ctx.scoreHole(hole)
 		if err != nil {
 			return nil, fmt.Errorf("failed to calculate hole result: %w", err)
 		}
 
+		if par := ctx.par(holeNum); par != nil {
+			strokeIndex := ctx.course[holeNum].StrokeIndex
+			holeResult.StrokeIndex = &strokeIndex
+			for _, score := range holeResult.PlayerScores {
+				toPar[score.UserID] += score.Counted() - *par
+			}
+		}
+
 		team1Points += holeResult.Team1Points
 		team2Points += holeResult.Team2Points
 		holesCompleted++
-	}
 
-	holesRemaining := match.Holes - holesCompleted
-	matchComplete := holesCompleted == match.Holes
-
-	// Determine winner
-	var winnerTeamID *string
-	if matchComplete {
-		if team1Points > team2Points {
-			winnerTeamID = &match.Team1ID
-		} else if team2Points > team1Points {
-			winnerTeamID = &match.Team2ID
+		if isTotalFormat {
+			team1Total += *holeResult.Team1Score
+			team2Total += *holeResult.Team2Score
+			holeResult.Team1Up = team1Total - team2Total
+			if totals.lowerWins() {
+				holeResult.Team1Up = -holeResult.Team1Up
+			}
+			holeResults = append(holeResults, *holeResult)
+			continue
 		}
-		// If points are equal, it's a tie (winnerTeamID remains nil)
-	}
 
-	return &MatchStatus{
-		Team1TotalPoints: team1Points,
-		Team2TotalPoints: team2Points,
-		HolesCompleted:   holesCompleted,
-		HolesRemaining:   holesRemaining,
-		MatchComplete:    matchComplete,
-		WinnerTeamID:     winnerTeamID,
-	}, nil
-}
-
-func (s *ScoringService) calculateHoleResult(match *models.Match, holeNumber int, scores []models.Score) (*HoleResult, error) {
-	// For now, we'll use a simple approach based on the match format ID
-	// In a more complete implementation, we'd fetch the match format from the database
-
-	// Default to match play scoring for now
-	return s.calculateMatchPlayHole(match, holeNumber, scores)
-}
-
-func (s *ScoringService) calculateMatchPlayHole(match *models.Match, holeNumber int, scores []models.Score) (*HoleResult, error) {
-	// Group scores by team (simplified - assumes we know which users are on which team)
-	team1Scores := []int{}
-	team2Scores := []int{}
-
-	// TODO: In a real implementation, you'd need to look up team memberships
-	// For now, we'll assume alternating assignment (first score = team1, second = team2, etc.)
-	for i, score := range scores {
-		if i%2 == 0 {
-			team1Scores = append(team1Scores, score.Strokes)
-		} else {
-			team2Scores = append(team2Scores, score.Strokes)
+		if swing > 1 {
+			team1Up += int(math.Round(holeResult.Team1Points - holeResult.Team2Points))
+		} else if holeResult.WinnerTeamID != nil {
+			if *holeResult.WinnerTeamID == ctx.match.Team1ID {
+				team1Up++
+			} else {
+				team1Up--
+			}
+		}
+		holeResult.Team1Up = team1Up
+		holeResults = append(holeResults, *holeResult)
+
+		// Once one side leads by more than can be won back, the rest of the match isn't played.
+		// A Nassau always plays out since the back nine and presses are separate bets. Winning
+		// the last hole finishes the match as usual rather than closing it out.
+		remaining := len(ctx.holes) - holesCompleted
+		if !isNassau && remaining > 0 && abs(team1Up) > swing*remaining {
+			closedOut = true
+			break
 		}
 	}
 
-	if len(team1Scores) == 0 || len(team2Scores) == 0 {
-		return nil, fmt.Errorf("insufficient scores for match play")
-	}
+	holesRemaining := len(ctx.holes) - holesCompleted
+	matchComplete := closedOut || holesCompleted == len(ctx.holes)
 
-	// For match play, take the best score from each team
-	team1Best := team1Scores[0]
-	for _, score := range team1Scores {
-		if score < team1Best {
-			team1Best = score
-		}
+	// Total formats measure the margin in strokes or points rather than holes
+	scoringUnit := "holes"
+	if swing > 1 {
+		scoringUnit = "points"
 	}
-
-	team2Best := team2Scores[0]
-	for _, score := range team2Scores {
-		if score < team2Best {
-			team2Best = score
+	if isTotalFormat {
+		team1Up = team1Total - team2Total
+		scoringUnit = "points"
+		if totals.lowerWins() {
+			team1Up = -team1Up
+			scoringUnit = "strokes"
 		}
 	}
 
-	result := &HoleResult{
-		HoleNumber:   holeNumber,
-		Team1Score:   &team1Best,
-		Team2Score:   &team2Best,
-		PlayerScores: scores,
-		Team1Points:  0,
-		Team2Points:  0,
+	var leaderTeamID *string
+	if team1Up > 0 {
+		leaderTeamID = &ctx.match.Team1ID
+	} else if team1Up < 0 {
+		leaderTeamID = &ctx.match.Team2ID
 	}
 
-	if team1Best < team2Best {
-		result.Team1Points = 1
-		result.WinnerTeamID = &match.Team1ID
-	} else if team2Best < team1Best {
-		result.Team2Points = 1
-		result.WinnerTeamID = &match.Team2ID
-	}
-	// If scores are equal, it's a halve (0.5 points each)
-	if team1Best == team2Best {
-		result.Team1Points = 0.5
-		result.Team2Points = 0.5
-	}
-
-	return result, nil
-}
-
-func (s *ScoringService) calculateBestBallHole(match *models.Match, holeNumber int, scores []models.Score) (*HoleResult, error) {
-	// Similar logic to match play - use the best score from each team
-	return s.calculateMatchPlayHole(match, holeNumber, scores)
-}
-
-func (s *ScoringService) calculateScrambleHole(match *models.Match, holeNumber int, scores []models.Score) (*HoleResult, error) {
-	// For scramble, each team should have one combined score
-	// This is a simplified implementation
-	team1Scores := []int{}
-	team2Scores := []int{}
-
-	for i, score := range scores {
-		if i%2 == 0 {
-			team1Scores = append(team1Scores, score.Strokes)
-		} else {
-			team2Scores = append(team2Scores, score.Strokes)
-		}
+	// Determine winner
+	var winnerTeamID *string
+	if matchComplete {
+		// If the match is all square, it's a tie (winnerTeamID remains nil)
+		winnerTeamID = leaderTeamID
 	}
 
-	if len(team1Scores) == 0 || len(team2Scores) == 0 {
-		return nil, fmt.Errorf("insufficient scores for scramble")
+	status := &MatchStatus{
+		Team1TotalPoints: team1Points,
+		Team2TotalPoints: team2Points,
+		HolesCompleted:   holesCompleted,
+		HolesRemaining:   holesRemaining,
+		MatchComplete:    matchComplete,
+		WinnerTeamID:     winnerTeamID,
+		Team1Up:          team1Up,
+		LeaderTeamID:     leaderTeamID,
+		Dormie:           !isTotalFormat && !isNassau && !matchComplete && team1Up != 0 && abs(team1Up) == swing*holesRemaining,
+		ClosedOut:        closedOut,
+		ScoringUnit:      scoringUnit,
+		PlayingHandicaps: ctx.playing,
+		PlayerToPar:      toPar,
+		HoleResults:      holeResults,
 	}
-
-	// Take the first score from each team (representing the team's combined score)
-	team1Score := team1Scores[0]
-	team2Score := team2Scores[0]
-
-	result := &HoleResult{
-		HoleNumber:   holeNumber,
-		Team1Score:   &team1Score,
-		Team2Score:   &team2Score,
-		PlayerScores: scores,
-		Team1Points:  0,
-		Team2Points:  0,
+	if isTotalFormat {
+		status.Team1Total = &team1Total
+		status.Team2Total = &team2Total
 	}
-
-	if team1Score < team2Score {
-		result.Team1Points = 1
-		result.WinnerTeamID = &match.Team1ID
-	} else if team2Score < team1Score {
-		result.Team2Points = 1
-		result.WinnerTeamID = &match.Team2ID
-	} else {
-		result.Team1Points = 0.5
-		result.Team2Points = 0.5
+	if closedOut {
+		status.HolesUnplayed = holesRemaining
 	}
+	status.ResultText = resultText(status)
 
-	return result, nil
+	return status, nil
 }
 
-func (s *ScoringService) calculateAlternateShotHole(match *models.Match, holeNumber int, scores []models.Score) (*HoleResult, error) {
-	// Similar to scramble - teams have combined scores
-	return s.calculateScrambleHole(match, holeNumber, scores)
+// resultText formats the match in standard match play notation, or as a stroke
+// or points margin for total formats
+func resultText(status *MatchStatus) string {
+	margin := abs(status.Team1Up)
+	switch {
+	case status.ClosedOut && status.ScoringUnit == "holes":
+		return fmt.Sprintf("%d&%d", margin, status.HolesUnplayed)
+	case margin == 1 && status.ScoringUnit == "strokes":
+		return "1 stroke"
+	case margin == 1 && status.ScoringUnit == "points":
+		return "1 pt"
+	case margin > 0 && status.ScoringUnit == "strokes":
+		return fmt.Sprintf("%d strokes", margin)
+	case margin > 0 && status.ScoringUnit == "points":
+		return fmt.Sprintf("%d pts", margin)
+	case margin > 0:
+		return fmt.Sprintf("%d UP", margin)
+	case status.MatchComplete:
+		return "Halved"
+	default:
+		return "A/S"
+	}
 }
 
-func (s *ScoringService) calculateHighLowHole(match *models.Match, holeNumber int, scores []models.Score) (*HoleResult, error) {
-	// High-Low combines the highest and lowest scores from each team
-	team1Scores := []int{}
-	team2Scores := []int{}
-
-	for i, score := range scores {
-		if i%2 == 0 {
-			team1Scores = append(team1Scores, score.Strokes)
-		} else {
-			team2Scores = append(team2Scores, score.Strokes)
-		}
+// scorerForMatch looks up the match's format and returns it with the scorer for its scoring_type
+func (s *ScoringService) scorerForMatch(match *models.Match) (*models.MatchFormatDefinition, FormatScorer, error) {
+	format, err := s.repo.GetMatchFormat(match.MatchFormatID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load match format: %w", err)
 	}
 
-	if len(team1Scores) < 2 || len(team2Scores) < 2 {
-		return nil, fmt.Errorf("high-low requires at least 2 players per team")
+	scorer, err := newFormatScorer(format)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Calculate high + low for each team
-	team1High := team1Scores[0]
-	team1Low := team1Scores[0]
-	for _, score := range team1Scores {
-		if score > team1High {
-			team1High = score
-		}
-		if score < team1Low {
-			team1Low = score
-		}
-	}
+	return format, scorer, nil
+}
 
-	team2High := team2Scores[0]
-	team2Low := team2Scores[0]
-	for _, score := range team2Scores {
-		if score > team2High {
-			team2High = score
-		}
-		if score < team2Low {
-			team2Low = score
+// splitHoleScores assigns each score on a hole to its player's side. Scores from
+// players who aren't on the match roster are ignored.
+func splitHoleScores(match *models.Match, roster *MatchRoster, holeNumber int, scores []models.Score) *HoleInput {
+	hole := &HoleInput{Match: match, HoleNumber: holeNumber}
+	for _, score := range scores {
+		switch roster.side(score.UserID) {
+		case 1:
+			hole.Team1 = append(hole.Team1, score)
+		case 2:
+			hole.Team2 = append(hole.Team2, score)
 		}
 	}
-
-	team1Total := team1High + team1Low
-	team2Total := team2High + team2Low
-
-	result := &HoleResult{
-		HoleNumber:   holeNumber,
-		Team1Score:   &team1Total,
-		Team2Score:   &team2Total,
-		PlayerScores: scores,
-		Team1Points:  0,
-		Team2Points:  0,
-	}
-
-	if team1Total < team2Total {
-		result.Team1Points = 1
-		result.WinnerTeamID = &match.Team1ID
-	} else if team2Total < team1Total {
-		result.Team2Points = 1
-		result.WinnerTeamID = &match.Team2ID
-	} else {
-		result.Team1Points = 0.5
-		result.Team2Points = 0.5
-	}
-
-	return result, nil
-}
-
-func (s *ScoringService) calculateShambleHole(match *models.Match, holeNumber int, scores []models.Score) (*HoleResult, error) {
-	// Shamble typically uses best ball after the tee shot
-	return s.calculateBestBallHole(match, holeNumber, scores)
+	return hole
 }
@@ -0,0 +1,34 @@
+package scoring
+
+import "testing"
+
+func TestResultText(t *testing.T) {
+	tests := []struct {
+		name   string
+		status MatchStatus
+		want   string
+	}{
+		{"all square", MatchStatus{ScoringUnit: "holes"}, "A/S"},
+		{"leading", MatchStatus{ScoringUnit: "holes", Team1Up: 2, HolesRemaining: 5}, "2 UP"},
+		{"trailing", MatchStatus{ScoringUnit: "holes", Team1Up: -3, HolesRemaining: 4}, "3 UP"},
+		{"dormie", MatchStatus{ScoringUnit: "holes", Team1Up: 2, HolesRemaining: 2, Dormie: true}, "2 UP"},
+		{"won on the last hole", MatchStatus{ScoringUnit: "holes", Team1Up: 1, MatchComplete: true}, "1 UP"},
+		{"closed out", MatchStatus{ScoringUnit: "holes", Team1Up: 3, MatchComplete: true, ClosedOut: true, HolesUnplayed: 2}, "3&2"},
+		{"closed out by team 2", MatchStatus{ScoringUnit: "holes", Team1Up: -5, MatchComplete: true, ClosedOut: true, HolesUnplayed: 4}, "5&4"},
+		{"halved", MatchStatus{ScoringUnit: "holes", MatchComplete: true}, "Halved"},
+		{"one stroke", MatchStatus{ScoringUnit: "strokes", Team1Up: 1}, "1 stroke"},
+		{"strokes", MatchStatus{ScoringUnit: "strokes", Team1Up: -4, MatchComplete: true}, "4 strokes"},
+		{"one point", MatchStatus{ScoringUnit: "points", Team1Up: -1}, "1 pt"},
+		{"points", MatchStatus{ScoringUnit: "points", Team1Up: 6}, "6 pts"},
+		{"split high-low closed out in points", MatchStatus{ScoringUnit: "points", Team1Up: 5, MatchComplete: true, ClosedOut: true, HolesUnplayed: 2}, "5 pts"},
+		{"level on strokes at the finish", MatchStatus{ScoringUnit: "strokes", MatchComplete: true}, "Halved"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resultText(&tt.status); got != tt.want {
+				t.Errorf("resultText = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,245 @@
+package scoring
+
+import (
+	"math"
+	"sort"
+
+	"mayhamapi/models"
+)
+
+// SkinsHole is the outcome of one hole in a round's skins game
+type SkinsHole struct {
+	HoleNumber   int     `json:"hole_number"`
+	Par          *int    `json:"par,omitempty"`
+	WinnerUserID *string `json:"winner_user_id,omitempty"`
+	WinnerName   *string `json:"winner_name,omitempty"`
+	WinningScore *int    `json:"winning_score,omitempty"` // gross or net, following the game's mode
+	Skins        int     `json:"skins"`                   // skins won on the hole, including carryovers
+	CarriedOver  bool    `json:"carried_over"`
+	Pending      bool    `json:"pending"` // not every player has posted a score yet
+}
+
+// SkinsPlayer is one player's skins total for the round
+type SkinsPlayer struct {
+	UserID   string  `json:"user_id"`
+	Name     string  `json:"name"`
+	Skins    int     `json:"skins"`
+	Holes    []int   `json:"holes"`
+	Winnings float64 `json:"winnings"`
+}
+
+// SkinsResult is the state of a round's skins game
+type SkinsResult struct {
+	Game      models.SkinsGame `json:"game"`
+	SkinValue float64          `json:"skin_value"`      // pot divided by the skins won so far
+	Unclaimed int              `json:"unclaimed_skins"` // skins still carrying over after the last decided hole
+	Holes     []SkinsHole      `json:"holes"`
+	Players   []SkinsPlayer    `json:"players"`
+}
+
+// CalculateSkins works out the skins game for a round from the scores submitted to
+// its matches. Every player in an individual-ball match takes part; scramble and
+// alternate shot matches are left out since they don't produce individual scores.
+func (s *ScoringService) CalculateSkins(roundID string) (*SkinsResult, error) {
+	game, err := s.repo.GetSkinsGame(roundID)
+	if err != nil {
+		if err.Error() != "skins game not found" {
+			return nil, err
+		}
+		// Rounds without settings play gross skins with carryovers and no pot
+		game = &models.SkinsGame{RoundID: roundID, Mode: models.SkinsGross, Carryovers: true}
+	}
+
+	matches, err := s.repo.GetMatchesByRound(roundID)
+	if err != nil {
+		return nil, err
+	}
+
+	round := &skinsRound{
+		course:   make(map[int]models.Hole),
+		expected: make(map[int][]string),
+		strokes:  make(map[int]map[string]int),
+	}
+	courseHandicaps := make(map[string]float64)
+	var userIDs []string
+	for i := range matches {
+		match := &matches[i]
+		ctx, err := s.loadMatchContext(match)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := ctx.scorer.(teamBallScorer); ok {
+			continue
+		}
+		if len(ctx.course) > 0 {
+			round.course = ctx.course
+		}
+
+		// Holes are settled in playing order: the first match's, then any holes only later matches play
+		for _, holeNum := range ctx.holes {
+			if _, ok := round.expected[holeNum]; !ok {
+				round.holes = append(round.holes, holeNum)
+				round.expected[holeNum] = nil
+			}
+		}
+
+		for _, userID := range ctx.roster.players() {
+			userIDs = append(userIDs, userID)
+			courseHandicaps[userID] = courseHandicap(ctx.roster.Handicaps[userID], ctx.tees[userID])
+			for _, holeNum := range ctx.holes {
+				round.expected[holeNum] = append(round.expected[holeNum], userID)
+			}
+		}
+
+		matchScores, err := s.repo.GetMatchScores(match.ID)
+		if err != nil {
+			return nil, err
+		}
+		concessions, err := s.repo.GetMatchConcessions(match.ID)
+		if err != nil {
+			return nil, err
+		}
+		holeScores := make(map[int][]models.Score)
+		for _, score := range matchScores {
+			holeScores[score.HoleNumber] = append(holeScores[score.HoleNumber], score)
+		}
+		// A conceded putt counts toward skins just as it does in the match
+		ctx.applyConcessions(concessions, holeScores)
+
+		// Skins are played on the strokes taken: the match format's caps don't apply
+		for holeNum, scores := range holeScores {
+			if round.strokes[holeNum] == nil {
+				round.strokes[holeNum] = make(map[string]int)
+			}
+			for _, score := range scores {
+				round.strokes[holeNum][score.UserID] = score.Strokes
+			}
+		}
+	}
+
+	// Net skins give every player their full course handicap, spread across the holes played
+	if game.Mode == models.SkinsNet {
+		playing := make(map[string]int, len(courseHandicaps))
+		for userID, handicap := range courseHandicaps {
+			playing[userID] = int(math.Round(handicap * float64(len(round.holes)) / 18))
+		}
+		layout := &matchContext{holes: round.holes, course: round.course}
+		round.allocation = allocateStrokes(&models.Match{HandicapMode: models.HandicapFullHandicap}, playing, round.holes, layout.strokeIndexes())
+	}
+
+	result := round.settle(game)
+
+	names := make(map[string]string)
+	if len(userIDs) > 0 {
+		users, err := s.repo.GetUsersByIDs(userIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range users {
+			names[user.ID] = user.Name
+		}
+	}
+	for i := range result.Holes {
+		if winner := result.Holes[i].WinnerUserID; winner != nil {
+			name := names[*winner]
+			result.Holes[i].WinnerName = &name
+		}
+	}
+
+	for i := range result.Players {
+		result.Players[i].Name = names[result.Players[i].UserID]
+	}
+	sort.Slice(result.Players, func(i, j int) bool {
+		if result.Players[i].Skins != result.Players[j].Skins {
+			return result.Players[i].Skins > result.Players[j].Skins
+		}
+		return result.Players[i].Name < result.Players[j].Name
+	})
+
+	return result, nil
+}
+
+// skinsRound is what a round's skins game is settled from
+type skinsRound struct {
+	holes      []int                  // in the order they're settled
+	course     map[int]models.Hole    // the round's scorecard; empty without course data
+	expected   map[int][]string       // players due a score, by hole number
+	strokes    map[int]map[string]int // strokes taken by hole number then user ID
+	allocation StrokeAllocation       // handicap strokes for net skins
+}
+
+// settle works out who won each hole and how the pot is shared. Player names are left
+// for the caller to fill in.
+func (round *skinsRound) settle(game *models.SkinsGame) *SkinsResult {
+	result := &SkinsResult{Game: *game, Holes: []SkinsHole{}, Players: []SkinsPlayer{}}
+	won := make(map[string]*SkinsPlayer)
+	var winners []string
+	carry := 0
+	decided := true
+	for _, holeNum := range round.holes {
+		entry := SkinsHole{HoleNumber: holeNum}
+		if hole, ok := round.course[holeNum]; ok {
+			entry.Par = &hole.Par
+		}
+
+		// A hole can't be settled until everyone has posted, and neither can any hole
+		// after it since the carryover isn't known yet
+		for _, userID := range round.expected[holeNum] {
+			if _, ok := round.strokes[holeNum][userID]; !ok {
+				decided = false
+			}
+		}
+		if !decided {
+			entry.Pending = true
+			result.Holes = append(result.Holes, entry)
+			continue
+		}
+
+		best, count, winner := math.MaxInt, 0, ""
+		for _, userID := range round.expected[holeNum] {
+			score := round.strokes[holeNum][userID] - round.allocation.forHole(holeNum)[userID]
+			if score < best {
+				best, count, winner = score, 1, userID
+			} else if score == best {
+				count++
+			}
+		}
+
+		// Validation takes a gross birdie, even in net skins. It needs par, so holes
+		// without course data are never held back.
+		validated := !game.ValidationBirdie || entry.Par == nil || round.strokes[holeNum][winner] < *entry.Par
+		if count == 1 && validated {
+			entry.WinnerUserID = &winner
+			entry.WinningScore = &best
+			entry.Skins = 1 + carry
+			carry = 0
+
+			if won[winner] == nil {
+				won[winner] = &SkinsPlayer{UserID: winner, Holes: []int{}}
+				winners = append(winners, winner)
+			}
+			won[winner].Skins += entry.Skins
+			won[winner].Holes = append(won[winner].Holes, holeNum)
+		} else if game.Carryovers {
+			entry.CarriedOver = true
+			carry++
+		}
+		result.Holes = append(result.Holes, entry)
+	}
+	result.Unclaimed = carry
+
+	// The pot is shared across the skins actually won
+	totalSkins := 0
+	for _, player := range won {
+		totalSkins += player.Skins
+	}
+	if totalSkins > 0 {
+		result.SkinValue = math.Round(game.Pot/float64(totalSkins)*100) / 100
+	}
+	for _, userID := range winners {
+		player := won[userID]
+		player.Winnings = math.Round(game.Pot*float64(player.Skins)/float64(totalSkins)*100) / 100
+		result.Players = append(result.Players, *player)
+	}
+	return result
+}
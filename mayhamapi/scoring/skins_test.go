@@ -0,0 +1,196 @@
+package scoring
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"mayhamapi/models"
+)
+
+// skinsHoles lays out a skins game for players a, b and c over par-4 holes numbered from 1,
+// with the strokes each player took on each hole. A player missing from a hole hasn't posted.
+func skinsHoles(strokes ...map[string]int) *skinsRound {
+	round := &skinsRound{
+		course:   make(map[int]models.Hole),
+		expected: make(map[int][]string),
+		strokes:  make(map[int]map[string]int),
+	}
+	for i, hole := range strokes {
+		holeNum := i + 1
+		round.holes = append(round.holes, holeNum)
+		round.course[holeNum] = models.Hole{HoleNumber: holeNum, Par: 4}
+		round.expected[holeNum] = []string{"a", "b", "c"}
+		round.strokes[holeNum] = hole
+	}
+	return round
+}
+
+// skinsOutcome sums up a hole: the winner and skins won, "carry", "pending" or "-" when
+// nobody won and nothing carried
+func skinsOutcome(hole SkinsHole) string {
+	switch {
+	case hole.Pending:
+		return "pending"
+	case hole.CarriedOver:
+		return "carry"
+	case hole.WinnerUserID != nil:
+		return fmt.Sprintf("%s:%d", *hole.WinnerUserID, hole.Skins)
+	default:
+		return "-"
+	}
+}
+
+func TestSettleSkins(t *testing.T) {
+	tests := []struct {
+		name       string
+		game       models.SkinsGame
+		round      *skinsRound
+		allocation StrokeAllocation
+		holes      []string
+		unclaimed  int
+		winnings   map[string]float64
+		skinValue  float64
+	}{
+		{
+			name: "outright low score wins the skin",
+			game: models.SkinsGame{Carryovers: true},
+			round: skinsHoles(
+				map[string]int{"a": 4, "b": 5, "c": 5},
+				map[string]int{"a": 5, "b": 3, "c": 4},
+			),
+			holes:    []string{"a:1", "b:1"},
+			winnings: map[string]float64{"a": 0, "b": 0},
+		},
+		{
+			name: "ties carry over to the next winner",
+			game: models.SkinsGame{Carryovers: true, Pot: 30},
+			round: skinsHoles(
+				map[string]int{"a": 4, "b": 4, "c": 5},
+				map[string]int{"a": 4, "b": 4, "c": 4},
+				map[string]int{"a": 5, "b": 5, "c": 3},
+			),
+			holes:     []string{"carry", "carry", "c:3"},
+			winnings:  map[string]float64{"c": 30},
+			skinValue: 10,
+		},
+		{
+			name: "without carryovers a tied skin is lost",
+			game: models.SkinsGame{},
+			round: skinsHoles(
+				map[string]int{"a": 4, "b": 4, "c": 5},
+				map[string]int{"a": 5, "b": 5, "c": 3},
+			),
+			holes:    []string{"-", "c:1"},
+			winnings: map[string]float64{"c": 0},
+		},
+		{
+			name: "skins still carrying after the last hole are unclaimed",
+			game: models.SkinsGame{Carryovers: true},
+			round: skinsHoles(
+				map[string]int{"a": 3, "b": 4, "c": 5},
+				map[string]int{"a": 4, "b": 4, "c": 5},
+				map[string]int{"a": 4, "b": 4, "c": 4},
+			),
+			holes:     []string{"a:1", "carry", "carry"},
+			unclaimed: 2,
+			winnings:  map[string]float64{"a": 0},
+		},
+		{
+			name: "validation birdie holds back a par",
+			game: models.SkinsGame{Carryovers: true, ValidationBirdie: true},
+			round: skinsHoles(
+				map[string]int{"a": 4, "b": 5, "c": 5},
+				map[string]int{"a": 5, "b": 3, "c": 5},
+			),
+			holes:    []string{"carry", "b:2"},
+			winnings: map[string]float64{"b": 0},
+		},
+		{
+			name: "validation birdie is gross in net skins",
+			game: models.SkinsGame{Mode: models.SkinsNet, Carryovers: true, ValidationBirdie: true},
+			round: skinsHoles(
+				map[string]int{"a": 4, "b": 5, "c": 5},
+				map[string]int{"a": 5, "b": 3, "c": 5},
+			),
+			// a's net birdie on the first hole is a gross par
+			allocation: StrokeAllocation{1: {"a": 1}},
+			holes:      []string{"carry", "b:2"},
+			winnings:   map[string]float64{"b": 0},
+		},
+		{
+			name: "net strokes decide the hole",
+			game: models.SkinsGame{Mode: models.SkinsNet, Carryovers: true},
+			round: skinsHoles(
+				map[string]int{"a": 4, "b": 4, "c": 5},
+			),
+			allocation: StrokeAllocation{1: {"c": 2}},
+			holes:      []string{"c:1"},
+			winnings:   map[string]float64{"c": 0},
+		},
+		{
+			name: "a missing score holds up that hole and every one after",
+			game: models.SkinsGame{Carryovers: true},
+			round: skinsHoles(
+				map[string]int{"a": 3, "b": 4, "c": 5},
+				map[string]int{"a": 4, "b": 4},
+				map[string]int{"a": 5, "b": 3, "c": 5},
+			),
+			holes:    []string{"a:1", "pending", "pending"},
+			winnings: map[string]float64{"a": 0},
+		},
+		{
+			name: "pot shared by skins won",
+			game: models.SkinsGame{Carryovers: true, Pot: 100},
+			round: skinsHoles(
+				map[string]int{"a": 3, "b": 4, "c": 5},
+				map[string]int{"a": 4, "b": 3, "c": 5},
+				map[string]int{"a": 4, "b": 4, "c": 5},
+				map[string]int{"a": 4, "b": 5, "c": 3},
+			),
+			holes:     []string{"a:1", "b:1", "carry", "c:2"},
+			winnings:  map[string]float64{"a": 25, "b": 25, "c": 50},
+			skinValue: 25,
+		},
+		{
+			name: "uneven shares are rounded to the cent",
+			game: models.SkinsGame{Carryovers: true, Pot: 100},
+			round: skinsHoles(
+				map[string]int{"a": 3, "b": 4, "c": 5},
+				map[string]int{"a": 4, "b": 3, "c": 5},
+				map[string]int{"a": 4, "b": 5, "c": 3},
+			),
+			holes:     []string{"a:1", "b:1", "c:1"},
+			winnings:  map[string]float64{"a": 33.33, "b": 33.33, "c": 33.33},
+			skinValue: 33.33,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.round.allocation = tt.allocation
+			result := tt.round.settle(&tt.game)
+
+			var holes []string
+			for _, hole := range result.Holes {
+				holes = append(holes, skinsOutcome(hole))
+			}
+			if !reflect.DeepEqual(holes, tt.holes) {
+				t.Errorf("holes = %v, want %v", holes, tt.holes)
+			}
+			if result.Unclaimed != tt.unclaimed {
+				t.Errorf("unclaimed skins = %d, want %d", result.Unclaimed, tt.unclaimed)
+			}
+			winnings := make(map[string]float64)
+			for _, player := range result.Players {
+				winnings[player.UserID] = player.Winnings
+			}
+			if !reflect.DeepEqual(winnings, tt.winnings) {
+				t.Errorf("winnings = %v, want %v", winnings, tt.winnings)
+			}
+			if result.SkinValue != tt.skinValue {
+				t.Errorf("skin value = %v, want %v", result.SkinValue, tt.skinValue)
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package scoring
+
+import (
+	"mayhamapi/models"
+	"mayhamapi/repository"
+)
+
+// StatsRebuild summarises a player stats rebuild
+type StatsRebuild struct {
+	Tournaments int `json:"tournaments"`
+	Players     int `json:"players"`
+}
+
+// WithRepo returns a service that reads and writes through the given repository, e.g. a transaction
+func (s *ScoringService) WithRepo(repo *repository.Repository) *ScoringService {
+	return &ScoringService{repo: repo, odds: s.odds}
+}
+
+// RebuildPlayerStats regenerates player_stats from stored match results for one tournament,
+// or every tournament when tournamentID is nil, in one transaction
+func (s *ScoringService) RebuildPlayerStats(tournamentID *string) (*StatsRebuild, error) {
+	var tournamentIDs []string
+	if tournamentID != nil {
+		if _, err := s.repo.GetTournament(*tournamentID); err != nil {
+			return nil, err
+		}
+		tournamentIDs = []string{*tournamentID}
+	} else {
+		tournaments, err := s.repo.ListTournaments()
+		if err != nil {
+			return nil, err
+		}
+		for _, tournament := range tournaments {
+			tournamentIDs = append(tournamentIDs, tournament.ID)
+		}
+	}
+
+	rebuild := &StatsRebuild{}
+	err := s.repo.WithTx(func(tx *repository.Repository) error {
+		for _, id := range tournamentIDs {
+			players, err := s.WithRepo(tx).refreshPlayerStats(id)
+			if err != nil {
+				return err
+			}
+			rebuild.Players += players
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rebuild.Tournaments = len(tournamentIDs)
+	return rebuild, nil
+}
+
+// refreshPlayerStats recalculates a tournament's player_stats rows and returns how many
+// players it covers. Use a transaction-bound service so the stats are written alongside
+// the results they come from.
+func (s *ScoringService) refreshPlayerStats(tournamentID string) (int, error) {
+	stats, err := s.tallyPlayerStats(tournamentID)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.repo.ReplacePlayerStats(tournamentID, stats); err != nil {
+		return 0, err
+	}
+	return len(stats), nil
+}
+
+// refreshMatchPlayerStats recalculates the player_stats rows of just the players in one match,
+// from every match they've played in its tournament. Use a transaction-bound service so the
+// stats are written alongside the result that changed them.
+func (s *ScoringService) refreshMatchPlayerStats(tournamentID string, match *models.Match) error {
+	roster, err := s.loadRoster(match)
+	if err != nil {
+		return err
+	}
+
+	tally := newStatsTally(roster.players())
+	for _, userID := range roster.Team1 {
+		tally.player(userID, match.Team1ID)
+	}
+	for _, userID := range roster.Team2 {
+		tally.player(userID, match.Team2ID)
+	}
+	if len(tally.userIDs) == 0 {
+		return nil
+	}
+
+	matches, err := s.repo.GetTournamentPlayerMatches(tournamentID, tally.userIDs)
+	if err != nil {
+		return err
+	}
+	if err := s.tallyMatches(tally, matches); err != nil {
+		return err
+	}
+	return s.repo.UpsertPlayerStats(tally.stats(tournamentID))
+}
+
+// tallyPlayerStats adds up every team member's record in a tournament. Players are credited
+// with their side's result in each match they're rostered for, and its holes when the format
+// decides holes; anyone on a team who hasn't played yet gets an empty record.
+func (s *ScoringService) tallyPlayerStats(tournamentID string) ([]models.PlayerStats, error) {
+	teams, err := s.repo.GetTeamsByTournament(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := s.tournamentMatches(tournamentID)
+	if err != nil {
+		return nil, err
+	}
+
+	tally := newStatsTally(nil)
+	for _, team := range teams {
+		members, err := s.repo.GetTeamMembers(team.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range members {
+			tally.player(member.UserID, team.ID)
+		}
+	}
+
+	if err := s.tallyMatches(tally, matches); err != nil {
+		return nil, err
+	}
+	return tally.stats(tournamentID), nil
+}
+
+// statsTally collects player_stats records in the order players are first seen
+type statsTally struct {
+	userIDs []string
+	teams   map[string]string // team ID by user ID
+	records map[string]*standingRecord
+	only    map[string]bool // when set, players outside it aren't tallied
+}
+
+func newStatsTally(only []string) *statsTally {
+	tally := &statsTally{teams: make(map[string]string), records: make(map[string]*standingRecord)}
+	if only != nil {
+		tally.only = make(map[string]bool, len(only))
+		for _, userID := range only {
+			tally.only[userID] = true
+		}
+	}
+	return tally
+}
+
+// player returns the player's record, starting one on teamID if needed, or nil for a player outside the tally
+func (t *statsTally) player(userID, teamID string) *standingRecord {
+	if record, ok := t.records[userID]; ok {
+		return record
+	}
+	if t.only != nil && !t.only[userID] {
+		return nil
+	}
+	t.userIDs = append(t.userIDs, userID)
+	t.teams[userID] = teamID
+	t.records[userID] = &standingRecord{}
+	return t.records[userID]
+}
+
+// tallyMatches credits each player in the tally with their side's result in the matches.
+// Matches that haven't started are skipped.
+func (s *ScoringService) tallyMatches(tally *statsTally, matches []models.Match) error {
+	for i := range matches {
+		match := &matches[i]
+		results, err := s.repo.GetHoleResults(match.ID)
+		if err != nil {
+			return err
+		}
+		if match.Status != "completed" && len(results) == 0 {
+			continue
+		}
+		roster, err := s.loadRoster(match)
+		if err != nil {
+			return err
+		}
+		counts, err := s.countsHoles(match)
+		if err != nil {
+			return err
+		}
+		if !counts {
+			results = nil
+		}
+
+		tally.addMatch(match, roster, results)
+	}
+	return nil
+}
+
+// addMatch credits the tallied players on each side with the match's result once it's
+// completed, and with its decided holes so far
+func (t *statsTally) addMatch(match *models.Match, roster *MatchRoster, results []models.HoleResult) {
+	sides := []struct {
+		teamID          string
+		players         []string
+		points, against float64
+	}{
+		{match.Team1ID, roster.Team1, match.Team1Points, match.Team2Points},
+		{match.Team2ID, roster.Team2, match.Team2Points, match.Team1Points},
+	}
+	for _, side := range sides {
+		for _, userID := range side.players {
+			record := t.player(userID, side.teamID)
+			if record == nil {
+				continue
+			}
+			if match.Status == "completed" {
+				record.addMatch(side.points, side.against)
+			}
+			for _, result := range results {
+				record.addHole(result, side.teamID)
+			}
+		}
+	}
+}
+
+// stats returns the tally as player_stats rows
+func (t *statsTally) stats(tournamentID string) []models.PlayerStats {
+	stats := make([]models.PlayerStats, 0, len(t.userIDs))
+	for _, userID := range t.userIDs {
+		r := t.records[userID]
+		stats = append(stats, models.PlayerStats{
+			TournamentID:  tournamentID,
+			UserID:        userID,
+			TeamID:        t.teams[userID],
+			MatchesPlayed: r.matchesPlayed(),
+			MatchesWon:    r.matchesWon,
+			MatchesLost:   r.matchesLost,
+			MatchesTied:   r.tied,
+			PointsWon:     r.pointsWon,
+			PointsLost:    r.pointsLost,
+			HolesWon:      r.holesWon,
+			HolesLost:     r.holesLost,
+			HolesTied:     r.holesTied,
+		})
+	}
+	return stats
+}
+
+// statsRecord turns a stored player_stats row back into a standing record
+func statsRecord(stats models.PlayerStats) *standingRecord {
+	return &standingRecord{
+		pointsWon:   stats.PointsWon,
+		pointsLost:  stats.PointsLost,
+		matchesWon:  stats.MatchesWon,
+		matchesLost: stats.MatchesLost,
+		tied:        stats.MatchesTied,
+		holesWon:    stats.HolesWon,
+		holesLost:   stats.HolesLost,
+		holesTied:   stats.HolesTied,
+	}
+}
@@ -0,0 +1,108 @@
+package scoring
+
+import (
+	"reflect"
+	"testing"
+
+	"mayhamapi/models"
+)
+
+func TestStatsTally(t *testing.T) {
+	t1 := "t1"
+	fourballs := &MatchRoster{Team1: []string{"a1", "a2"}, Team2: []string{"b1", "b2"}}
+	singles := &MatchRoster{Team1: []string{"a1"}, Team2: []string{"b1"}}
+
+	type entry struct {
+		match   models.Match
+		roster  *MatchRoster
+		results []models.HoleResult
+	}
+	won := models.Match{Team1ID: "t1", Team2ID: "t2", Status: "completed", Team1Points: 1}
+	halved := models.Match{Team1ID: "t1", Team2ID: "t2", Status: "completed", Team1Points: 0.5, Team2Points: 0.5}
+	live := models.Match{Team1ID: "t1", Team2ID: "t2", Status: "in_progress"}
+
+	tests := []struct {
+		name    string
+		only    []string
+		members map[string]string // team members added before any matches, by user ID
+		matches []entry
+		want    []models.PlayerStats
+	}{
+		{
+			name:    "completed match with its holes",
+			matches: []entry{{won, singles, []models.HoleResult{{WinnerTeamID: &t1}, {}}}},
+			want: []models.PlayerStats{
+				{UserID: "a1", TeamID: "t1", MatchesPlayed: 1, MatchesWon: 1, PointsWon: 1, HolesWon: 1, HolesTied: 1},
+				{UserID: "b1", TeamID: "t2", MatchesPlayed: 1, MatchesLost: 1, PointsLost: 1, HolesLost: 1, HolesTied: 1},
+			},
+		},
+		{
+			name:    "match in progress counts only its holes",
+			matches: []entry{{live, singles, []models.HoleResult{{WinnerTeamID: &t1}}}},
+			want: []models.PlayerStats{
+				{UserID: "a1", TeamID: "t1", HolesWon: 1},
+				{UserID: "b1", TeamID: "t2", HolesLost: 1},
+			},
+		},
+		{
+			name:    "records add up across matches",
+			matches: []entry{{won, singles, nil}, {halved, fourballs, nil}},
+			want: []models.PlayerStats{
+				{UserID: "a1", TeamID: "t1", MatchesPlayed: 2, MatchesWon: 1, MatchesTied: 1, PointsWon: 1.5, PointsLost: 0.5},
+				{UserID: "b1", TeamID: "t2", MatchesPlayed: 2, MatchesLost: 1, MatchesTied: 1, PointsWon: 0.5, PointsLost: 1.5},
+				{UserID: "a2", TeamID: "t1", MatchesPlayed: 1, MatchesTied: 1, PointsWon: 0.5, PointsLost: 0.5},
+				{UserID: "b2", TeamID: "t2", MatchesPlayed: 1, MatchesTied: 1, PointsWon: 0.5, PointsLost: 0.5},
+			},
+		},
+		{
+			name:    "team members who haven't played get empty records",
+			members: map[string]string{"c1": "t1"},
+			matches: []entry{{won, singles, nil}},
+			want: []models.PlayerStats{
+				{UserID: "c1", TeamID: "t1"},
+				{UserID: "a1", TeamID: "t1", MatchesPlayed: 1, MatchesWon: 1, PointsWon: 1},
+				{UserID: "b1", TeamID: "t2", MatchesPlayed: 1, MatchesLost: 1, PointsLost: 1},
+			},
+		},
+		{
+			name:    "a tally for some players leaves the rest out",
+			only:    []string{"a2", "b2"},
+			matches: []entry{{won, singles, nil}, {halved, fourballs, nil}},
+			want: []models.PlayerStats{
+				{UserID: "a2", TeamID: "t1", MatchesPlayed: 1, MatchesTied: 1, PointsWon: 0.5, PointsLost: 0.5},
+				{UserID: "b2", TeamID: "t2", MatchesPlayed: 1, MatchesTied: 1, PointsWon: 0.5, PointsLost: 0.5},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tally := newStatsTally(tt.only)
+			for userID, teamID := range tt.members {
+				tally.player(userID, teamID)
+			}
+			for _, e := range tt.matches {
+				match := e.match
+				tally.addMatch(&match, e.roster, e.results)
+			}
+
+			got := tally.stats("cup")
+			for i := range tt.want {
+				tt.want[i].TournamentID = "cup"
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("stats =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatsRecordRoundTrip(t *testing.T) {
+	record := &standingRecord{pointsWon: 2.5, pointsLost: 1.5, matchesWon: 2, matchesLost: 1, tied: 1, holesWon: 20, holesLost: 15, holesTied: 19}
+	tally := newStatsTally(nil)
+	*tally.player("a1", "t1") = *record
+
+	if got := statsRecord(tally.stats("cup")[0]); !reflect.DeepEqual(got, record) {
+		t.Errorf("statsRecord = %+v, want %+v", *got, *record)
+	}
+}
@@ -0,0 +1,101 @@
+package scoring
+
+import (
+	"fmt"
+
+	"mayhamapi/models"
+)
+
+// maxStrokes is the most strokes accepted on a single hole
+const maxStrokes = 20
+
+// ScoreError describes why one submitted score was rejected
+type ScoreError struct {
+	Index      int    `json:"index"` // position in the submitted scores
+	UserID     string `json:"user_id"`
+	HoleNumber int    `json:"hole_number"`
+	Field      string `json:"field"` // hole_number, user_id or strokes
+	Message    string `json:"message"`
+}
+
+// validateHole checks a score's hole number belongs to the match: one of the holes it's
+// played over, or a playoff hole when the match must produce a winner
+func (ctx *matchContext) validateHole(holeNumber int) error {
+	for _, holeNum := range ctx.holes {
+		if holeNum == holeNumber {
+			return nil
+		}
+	}
+	if ctx.match.MustProduceWinner && holeNumber >= ctx.firstPlayoffHole() {
+		return nil
+	}
+	return fmt.Errorf("hole %d is not part of this match", holeNumber)
+}
+
+// ValidateScores checks submitted scores against the match's holes and roster and works
+// out each score's adjusted strokes under the format's caps. The adjusted values come back
+// in the same order as the scores; if any score is invalid, every problem found is
+// returned instead.
+func (s *ScoringService) ValidateScores(match *models.Match, scores []models.HoleScore) ([]int, []ScoreError, error) {
+	ctx, err := s.loadMatchContext(match)
+	if err != nil {
+		return nil, nil, err
+	}
+	adjusted, errs := ctx.validateScores(scores)
+	return adjusted, errs, nil
+}
+
+// validateScores checks scores against the loaded match, as ValidateScores does
+func (ctx *matchContext) validateScores(scores []models.HoleScore) ([]int, []ScoreError) {
+	var errs []ScoreError
+	adjusted := make([]int, len(scores))
+	seen := make(map[string]bool)
+	for i, score := range scores {
+		fail := func(field, message string) {
+			errs = append(errs, ScoreError{Index: i, UserID: score.UserID, HoleNumber: score.HoleNumber, Field: field, Message: message})
+		}
+
+		if err := ctx.validateHole(score.HoleNumber); err != nil {
+			fail("hole_number", err.Error())
+		}
+		if ctx.roster.side(score.UserID) == 0 {
+			fail("user_id", "player is not on the match roster")
+		}
+		if score.Strokes < 1 || score.Strokes > maxStrokes {
+			fail("strokes", fmt.Sprintf("strokes must be between 1 and %d", maxStrokes))
+		}
+
+		key := fmt.Sprintf("%s/%d", score.UserID, score.HoleNumber)
+		if seen[key] {
+			fail("hole_number", "more than one score for this player on this hole")
+		}
+		seen[key] = true
+
+		adjusted[i] = ctx.adjustedStrokes(score.UserID, score.HoleNumber, score.Strokes)
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return adjusted, nil
+}
+
+// adjustedStrokes applies the format's caps to a raw score. Net double bogey needs par,
+// so it only applies on holes with course data.
+func (ctx *matchContext) adjustedStrokes(userID string, holeNumber, strokes int) int {
+	courseHole := holeNumber
+	if holeNumber >= ctx.firstPlayoffHole() {
+		courseHole = ctx.playoffCourseHole(holeNumber)
+	}
+
+	adjusted := strokes
+	if ctx.config.PickUpAt > 0 && adjusted > ctx.config.PickUpAt {
+		adjusted = ctx.config.PickUpAt
+	}
+	if par := ctx.par(courseHole); ctx.config.NetDoubleBogey && par != nil {
+		if limit := *par + 2 + ctx.allocation.forHole(courseHole)[userID]; adjusted > limit {
+			adjusted = limit
+		}
+	}
+	return adjusted
+}
@@ -0,0 +1,113 @@
+package scoring
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"mayhamapi/models"
+)
+
+func TestValidateScores(t *testing.T) {
+	tests := []struct {
+		name              string
+		mustProduceWinner bool
+		scores            []models.HoleScore
+		adjusted          []int
+		errs              []string // index/field of each problem found
+	}{
+		{
+			name:     "valid scores",
+			scores:   []models.HoleScore{{UserID: "a1", HoleNumber: 1, Strokes: 4}, {UserID: "b1", HoleNumber: 1, Strokes: 5}},
+			adjusted: []int{4, 5},
+		},
+		{
+			name:   "hole outside the match",
+			scores: []models.HoleScore{{UserID: "a1", HoleNumber: 10, Strokes: 4}},
+			errs:   []string{"0/hole_number"},
+		},
+		{
+			name:              "playoff hole in a match that must produce a winner",
+			mustProduceWinner: true,
+			scores:            []models.HoleScore{{UserID: "a1", HoleNumber: 12, Strokes: 4}},
+			adjusted:          []int{4},
+		},
+		{
+			name:   "player not on the roster",
+			scores: []models.HoleScore{{UserID: "c1", HoleNumber: 1, Strokes: 4}},
+			errs:   []string{"0/user_id"},
+		},
+		{
+			name:   "strokes out of range",
+			scores: []models.HoleScore{{UserID: "a1", HoleNumber: 1, Strokes: 0}, {UserID: "b1", HoleNumber: 1, Strokes: maxStrokes + 1}},
+			errs:   []string{"0/strokes", "1/strokes"},
+		},
+		{
+			name:   "two scores for one player on a hole",
+			scores: []models.HoleScore{{UserID: "a1", HoleNumber: 1, Strokes: 4}, {UserID: "a1", HoleNumber: 1, Strokes: 5}},
+			errs:   []string{"1/hole_number"},
+		},
+		{
+			name:   "every problem is reported",
+			scores: []models.HoleScore{{UserID: "a1", HoleNumber: 1, Strokes: 4}, {UserID: "x", HoleNumber: 30, Strokes: 25}},
+			errs:   []string{"1/hole_number", "1/user_id", "1/strokes"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := singlesContext(9, matchPlayScorer{})
+			ctx.match.MustProduceWinner = tt.mustProduceWinner
+
+			adjusted, errs := ctx.validateScores(tt.scores)
+
+			var got []string
+			for _, e := range errs {
+				got = append(got, fmt.Sprintf("%d/%s", e.Index, e.Field))
+			}
+			if !reflect.DeepEqual(got, tt.errs) {
+				t.Errorf("errors = %v, want %v", got, tt.errs)
+			}
+			if !reflect.DeepEqual(adjusted, tt.adjusted) {
+				t.Errorf("adjusted = %v, want %v", adjusted, tt.adjusted)
+			}
+		})
+	}
+}
+
+func TestAdjustedStrokes(t *testing.T) {
+	course := map[int]models.Hole{1: {HoleNumber: 1, Par: 4}, 2: {HoleNumber: 2, Par: 3}}
+
+	tests := []struct {
+		name       string
+		config     models.FormatConfig
+		holeNumber int
+		strokes    int
+		allocation StrokeAllocation
+		want       int
+	}{
+		{"no caps", models.FormatConfig{}, 1, 11, nil, 11},
+		{"pick up", models.FormatConfig{PickUpAt: 8}, 1, 11, nil, 8},
+		{"under the pick up", models.FormatConfig{PickUpAt: 8}, 1, 7, nil, 7},
+		{"net double bogey", models.FormatConfig{NetDoubleBogey: true}, 1, 9, nil, 6},
+		{"net double bogey with a stroke", models.FormatConfig{NetDoubleBogey: true}, 1, 9, StrokeAllocation{1: {"a1": 1}}, 7},
+		{"net double bogey on a par 3", models.FormatConfig{NetDoubleBogey: true}, 2, 9, nil, 5},
+		{"net double bogey needs par", models.FormatConfig{NetDoubleBogey: true}, 3, 9, nil, 9},
+		{"lower of the two caps", models.FormatConfig{NetDoubleBogey: true, PickUpAt: 7}, 1, 9, nil, 6},
+		{"playoff hole follows the course hole replayed", models.FormatConfig{NetDoubleBogey: true}, 5, 9, nil, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A three-hole match, so hole 4 replays hole 1 and hole 5 replays hole 2
+			ctx := singlesContext(3, matchPlayScorer{})
+			ctx.config = tt.config
+			ctx.course = course
+			ctx.allocation = tt.allocation
+
+			if got := ctx.adjustedStrokes("a1", tt.holeNumber, tt.strokes); got != tt.want {
+				t.Errorf("adjustedStrokes = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}